@@ -0,0 +1,374 @@
+package equity
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/4gatepylon/GoPoker/poker"
+)
+
+// Equity is how much of the pot a hand wins on average over every way the board can run out. When
+// there are few enough boards left every one of them is evaluated (so the answer is exact), and
+// otherwise boards are sampled at random (Monte Carlo) from a seed so the same question always gets
+// the same answer. Either way the boards are split between goroutines on every CPU core. Counts are
+// kept per board (a tie between k hands is worth SHARE_UNITS / k to each of them) and added up in the
+// same order however the work was split, so the answer does not depend on that either.
+//
+// Players can also hold a range instead of known cards. Combos that share a card with the board, the
+// dead cards or a known hand can not be dealt, and neither can two combos that share a card with each
+// other. Enumeration goes over every deal of combos (counting each by the product of their weights)
+// and every board after it, while sampling deals combos in proportion to their weights and throws the
+// deal away whenever two of them clash, so every deal turns up as often as it would at the table.
+
+const (
+	MIN_PLAYERS           int    = 2
+	MAX_PLAYERS           int    = 10
+	DEFAULT_SAMPLES       int    = 100000  // Boards sampled by Monte Carlo unless asked otherwise
+	MAX_EXHAUSTIVE_BOARDS uint64 = 1 << 20 // Boards (over every deal of ranges) are enumerated when there are at most this many
+	SHARE_UNITS           uint64 = 2520    // Divisible by every number of players that can tie
+	samplesPerChunk       int    = 4096    // Monte Carlo samples share a seed in chunks of this size
+)
+
+type Args struct {
+	Hands   []poker.CardSet // Hole cards (two each) of every player in the hand (none for players holding a range)
+	Ranges  []Range         // Ranges of the players in the same order as the hands (nil for players with known cards)
+	Board   poker.CardSet   // Whatever is on the board so far (up to five cards)
+	Dead    poker.CardSet   // Cards known to be out of the deck (i.e. folded or burned)
+	Samples int             // Boards to sample when there are too many to enumerate (zero is the default)
+	Seed    int64           // Seed for sampling boards
+	Workers int             // Goroutines to split the boards between (zero is one per core)
+}
+
+// Percentages (out of 100) for every hand in the order they were given
+type Result struct {
+	Win    []float64 // Boards the hand wins outright
+	Tie    []float64 // Boards the hand splits with others
+	Equity []float64 // Share of the pot the hand wins on average
+	Boards uint64    // How many boards were evaluated
+	Exact  bool      // Whether every board was evaluated (rather than sampled)
+}
+
+// Wins, ties and shares of the pot (in SHARE_UNITS) over some boards, each counted by the weight of
+// the deal it was evaluated for
+type tally struct {
+	win    []float64
+	tie    []float64
+	share  []float64
+	weight float64
+	boards uint64
+}
+
+func newTally(players int) *tally {
+	return &tally{win: make([]float64, players), tie: make([]float64, players), share: make([]float64, players)}
+}
+
+func (t *tally) add(o *tally) {
+	for i := range t.win {
+		t.win[i] += o.win[i]
+		t.tie[i] += o.tie[i]
+		t.share[i] += o.share[i]
+	}
+	t.weight += o.weight
+	t.boards += o.boards
+}
+
+// return n choose k (as long as it fits)
+func choose(n int, k int) uint64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	var c uint64 = 1
+	for i := 1; i <= k; i++ {
+		c = c * uint64(n-k+i) / uint64(i)
+	}
+	return c
+}
+
+// The cards of a calculation once they have been checked
+type deal struct {
+	hands   []poker.CardSet   // Known hands (and nothing for players holding a range)
+	ranged  []int             // Players holding a range
+	combos  [][]poker.CardSet // Combos that can be dealt to each of the ranged players
+	weights [][]float64       // Weights of those combos
+	totals  [][]float64       // Running totals of the weights of those combos
+	deck    []poker.CardSet   // Cards that are not known to be anywhere
+}
+
+// Check that the cards make sense and return what is left to deal
+func checkArgs(args *Args) (*deal, error) {
+	if n := len(args.Hands); n < MIN_PLAYERS || n > MAX_PLAYERS {
+		return nil, fmt.Errorf("Equity is for %d to %d players but got %d", MIN_PLAYERS, MAX_PLAYERS, n)
+	}
+	if len(args.Ranges) > len(args.Hands) {
+		return nil, fmt.Errorf("Got %d ranges for %d players", len(args.Ranges), len(args.Hands))
+	}
+	if args.Board.Count() > 5 {
+		return nil, fmt.Errorf("The board has at most five cards but got %s", poker.CardSetToString(args.Board))
+	}
+	seen := args.Board
+	if seen&args.Dead != 0 {
+		return nil, fmt.Errorf("Dead cards %s are on the board", poker.CardSetToString(seen&args.Dead))
+	}
+	seen |= args.Dead
+	d := &deal{hands: append([]poker.CardSet{}, args.Hands...)}
+	for i, hand := range args.Hands {
+		if i < len(args.Ranges) && args.Ranges[i] != nil {
+			if hand != poker.NoCards {
+				return nil, fmt.Errorf("Hand %d should be left empty since it is given a range", i)
+			}
+			d.ranged = append(d.ranged, i)
+			continue
+		}
+		if hand.Count() != 2 {
+			return nil, fmt.Errorf("Hand %d should have two cards but has %s", i, poker.CardSetToString(hand))
+		}
+		if seen&hand != 0 {
+			return nil, fmt.Errorf("Hand %d shares %s with another hand, the board or the dead cards", i, poker.CardSetToString(seen&hand))
+		}
+		seen |= hand
+	}
+	for _, i := range d.ranged {
+		combos := args.Ranges[i].Remove(seen).Combos()
+		weights, totals := make([]float64, len(combos)), make([]float64, len(combos))
+		total := 0.0
+		for j, combo := range combos {
+			weights[j] = args.Ranges[i][combo]
+			// NaN fails every comparison so the weight has to be checked for being in range
+			if combo.Count() != 2 || !(weights[j] > 0 && weights[j] <= 1) {
+				return nil, fmt.Errorf("The range of hand %d has %s with weight %v", i, poker.CardSetToString(combo), weights[j])
+			}
+			total += weights[j]
+			totals[j] = total
+		}
+		if len(combos) == 0 {
+			return nil, fmt.Errorf("Every combo in the range of hand %d is blocked by known cards", i)
+		}
+		d.combos = append(d.combos, combos)
+		d.weights = append(d.weights, weights)
+		d.totals = append(d.totals, totals)
+	}
+	d.deck = poker.AllCards.Remove(seen).Cards()
+	if len(d.deck)-2*len(d.ranged) < 5-args.Board.Count() {
+		return nil, fmt.Errorf("Not enough cards left to finish the board")
+	}
+	if !d.deals(func([]poker.CardSet, float64) bool { return false }) {
+		return nil, fmt.Errorf("The ranges can not all be dealt without sharing a card")
+	}
+	return d, nil
+}
+
+// Visit every deal of combos to the ranged players that do not share a card, with its weight, until
+// the visit returns false; return whether there were any deals
+func (d *deal) deals(visit func(picks []poker.CardSet, weight float64) bool) bool {
+	picks := make([]poker.CardSet, len(d.ranged))
+	found, stop := false, false
+	var next func(k int, used poker.CardSet, weight float64)
+	next = func(k int, used poker.CardSet, weight float64) {
+		if k == len(d.ranged) {
+			found = true
+			stop = !visit(picks, weight)
+			return
+		}
+		for j, combo := range d.combos[k] {
+			if stop {
+				return
+			}
+			if combo&used != 0 {
+				continue
+			}
+			picks[k] = combo
+			next(k+1, used|combo, weight*d.weights[k][j])
+		}
+	}
+	next(0, poker.NoCards, 1)
+	return found
+}
+
+// Deal the ranged players combos (in proportion to their weights) that do not share a card and
+// return the cards dealt
+func (d *deal) pick(hands []poker.CardSet, rng *rand.Rand) poker.CardSet {
+	for {
+		var used poker.CardSet = poker.NoCards
+		for k, i := range d.ranged {
+			totals := d.totals[k]
+			hands[i] = d.combos[k][sort.SearchFloat64s(totals, rng.Float64()*totals[len(totals)-1])]
+			if hands[i]&used != 0 {
+				break
+			}
+			used |= hands[i]
+		}
+		if used.Count() == 2*len(d.ranged) {
+			return used
+		}
+	}
+}
+
+// The cards of the deck that were not dealt
+func (d *deal) live(used poker.CardSet) []poker.CardSet {
+	live := make([]poker.CardSet, 0, len(d.deck))
+	for _, card := range d.deck {
+		if card&used == 0 {
+			live = append(live, card)
+		}
+	}
+	return live
+}
+
+// Evaluate every hand on a full board and credit the best of them
+func (t *tally) score(hands []poker.CardSet, board poker.CardSet, values []uint64, weight float64) {
+	var best uint64 = 0
+	winners := 0
+	for i, hand := range hands {
+		values[i] = poker.EvaluateFast(hand | board)
+		if values[i] > best {
+			best, winners = values[i], 0
+		}
+		if values[i] == best {
+			winners++
+		}
+	}
+	for i, v := range values {
+		if v != best {
+			continue
+		}
+		if winners == 1 {
+			t.win[i] += weight
+		} else {
+			t.tie[i] += weight
+		}
+		t.share[i] += weight * float64(SHARE_UNITS/uint64(winners))
+	}
+	t.weight += weight
+	t.boards++
+}
+
+// Calculate the win, tie and equity percentages of every hand (or range)
+func Calculate(args *Args) (*Result, error) {
+	d, err := checkArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	workers := args.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	missing := 5 - args.Board.Count()
+	left := len(d.deck) - 2*len(d.ranged)
+	boards := choose(left, missing)
+	exact := boards <= MAX_EXHAUSTIVE_BOARDS
+	for k := 0; k < len(d.ranged) && exact; k++ {
+		boards *= uint64(len(d.combos[k]))
+		exact = boards <= MAX_EXHAUSTIVE_BOARDS
+	}
+
+	// Jobs are either a deal of the ranges and the first card of the boards to enumerate after it,
+	// or a chunk of samples
+	var picks [][]poker.CardSet
+	var weights []float64
+	firsts := left
+	if missing == 0 {
+		firsts = 1
+	}
+	var jobs int
+	if exact {
+		d.deals(func(p []poker.CardSet, weight float64) bool {
+			picks = append(picks, append([]poker.CardSet{}, p...))
+			weights = append(weights, weight)
+			return true
+		})
+		jobs = len(picks) * firsts
+	}
+	samples := args.Samples
+	if samples <= 0 {
+		samples = DEFAULT_SAMPLES
+	}
+	if !exact {
+		jobs = (samples + samplesPerChunk - 1) / samplesPerChunk
+	}
+
+	tallies := make([]*tally, jobs)
+	next := make(chan int, jobs)
+	for j := 0; j < jobs; j++ {
+		next <- j
+	}
+	close(next)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range next {
+				t := newTally(len(args.Hands))
+				if exact {
+					hands := append([]poker.CardSet{}, d.hands...)
+					var used poker.CardSet = poker.NoCards
+					for k, i := range d.ranged {
+						hands[i] = picks[j/firsts][k]
+						used |= hands[i]
+					}
+					enumerate(t, hands, args.Board, d.live(used), j%firsts, missing, weights[j/firsts])
+				} else {
+					n := samplesPerChunk
+					if j == jobs-1 {
+						n = samples - j*samplesPerChunk
+					}
+					sample(t, d, args.Board, missing, n, rand.New(rand.NewSource(args.Seed+int64(j))))
+				}
+				tallies[j] = t
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := newTally(len(args.Hands))
+	for _, t := range tallies {
+		total.add(t)
+	}
+	res := &Result{Boards: total.boards, Exact: exact}
+	for i := range args.Hands {
+		res.Win = append(res.Win, 100*total.win[i]/total.weight)
+		res.Tie = append(res.Tie, 100*total.tie[i]/total.weight)
+		res.Equity = append(res.Equity, 100*total.share[i]/(float64(SHARE_UNITS)*total.weight))
+	}
+	return res, nil
+}
+
+// Evaluate every board whose lowest new card is deck[first] (or the board itself if it is full)
+func enumerate(t *tally, hands []poker.CardSet, board poker.CardSet, deck []poker.CardSet, first int, missing int, weight float64) {
+	values := make([]uint64, len(hands))
+	if missing == 0 {
+		t.score(hands, board, values, weight)
+		return
+	}
+	var above poker.CardSet = poker.NoCards
+	for _, card := range deck[first+1:] {
+		above |= card
+	}
+	board |= deck[first]
+	for rest := poker.NewCombinations(above, missing-1); rest.Next(); {
+		t.score(hands, board|rest.Set(), values, weight)
+	}
+}
+
+// Evaluate n boards finished with cards drawn at random from the deck (after dealing the ranges)
+func sample(t *tally, d *deal, board poker.CardSet, missing int, n int, rng *rand.Rand) {
+	hands := append([]poker.CardSet{}, d.hands...)
+	values := make([]uint64, len(hands))
+	shuffled := append([]poker.CardSet{}, d.deck...)
+	for s := 0; s < n; s++ {
+		if len(d.ranged) > 0 {
+			shuffled = d.live(d.pick(hands, rng))
+		}
+		full := board
+		// only the first few cards of the deck need to be shuffled
+		for i := 0; i < missing; i++ {
+			j := i + rng.Intn(len(shuffled)-i)
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+			full |= shuffled[i]
+		}
+		t.score(hands, full, values, 1)
+	}
+}
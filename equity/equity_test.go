@@ -0,0 +1,173 @@
+package equity
+
+import (
+	"math"
+	"testing"
+
+	"github.com/4gatepylon/GoPoker/poker"
+)
+
+func near(a float64, b float64, within float64) bool {
+	return math.Abs(a-b) <= within
+}
+
+func mustCalculate(t *testing.T, args *Args) *Result {
+	res, err := Calculate(args)
+	if err != nil {
+		t.Fatalf("Failed to calculate equity: `%v`", err)
+	}
+	return res
+}
+
+func TestEquityChecksCards(t *testing.T) {
+	aces := poker.AceOfClubs | poker.AceOfDiamonds
+	var tests = []*Args{
+		{Hands: []poker.CardSet{aces}},
+		{Hands: []poker.CardSet{aces, aces}},
+		{Hands: []poker.CardSet{aces, poker.KingOfClubs}},
+		{Hands: []poker.CardSet{aces, poker.KingOfClubs | poker.KingOfDiamonds}, Dead: poker.AceOfClubs},
+		{Hands: []poker.CardSet{aces, poker.KingOfClubs | poker.KingOfDiamonds}, Board: poker.Twos | poker.Threes},
+	}
+	for _, args := range tests {
+		if _, err := Calculate(args); err == nil {
+			t.Errorf("Expected an error for hands %v on board %s", args.Hands, poker.CardSetToString(args.Board))
+		}
+	}
+	for _, weight := range []float64{0, -1, 2, math.NaN(), math.Inf(1)} {
+		r := Range{poker.KingOfClubs | poker.KingOfDiamonds: weight}
+		if _, err := Calculate(&Args{Hands: []poker.CardSet{aces, poker.NoCards}, Ranges: []Range{nil, r}}); err == nil {
+			t.Errorf("Expected an error for a range with weight %v", weight)
+		}
+	}
+}
+
+func TestEquityOnTheRiverIsExact(t *testing.T) {
+	board := poker.AceOfHearts | poker.KingOfSpades | poker.SevenOfClubs | poker.FourOfDiamonds | poker.TwoOfHearts
+	res := mustCalculate(t, &Args{Hands: []poker.CardSet{
+		poker.AceOfClubs | poker.QueenOfClubs,
+		poker.KingOfClubs | poker.KingOfDiamonds,
+		poker.AceOfDiamonds | poker.QueenOfDiamonds,
+	}, Board: board})
+	if !res.Exact || res.Boards != 1 || res.Win[1] != 100 || res.Equity[0] != 0 || res.Tie[2] != 0 {
+		t.Fatalf("Expected the set of kings to win every time but got %+v", res)
+	}
+}
+
+func TestEquityTiesSplitThePot(t *testing.T) {
+	// the same hand in different suits only loses to a flush (or wins with one)
+	res := mustCalculate(t, &Args{Hands: []poker.CardSet{
+		poker.AceOfClubs | poker.KingOfDiamonds,
+		poker.AceOfHearts | poker.KingOfSpades,
+	}, Board: poker.TwoOfClubs | poker.SevenOfHearts | poker.NineOfSpades})
+	if !res.Exact || res.Boards != 990 || res.Equity[0] != 50 || res.Equity[1] != 50 || res.Tie[0] != res.Tie[1] || res.Win[0] != res.Win[1] {
+		t.Fatalf("Expected an even split but got %+v", res)
+	}
+}
+
+func TestEquityMonteCarloMatchesEnumeration(t *testing.T) {
+	hands := []poker.CardSet{poker.AceOfSpades | poker.AceOfHearts, poker.KingOfClubs | poker.KingOfDiamonds}
+	board := poker.TwoOfClubs | poker.SevenOfDiamonds | poker.KingOfHearts
+	exact := mustCalculate(t, &Args{Hands: hands, Board: board})
+	if !exact.Exact || exact.Boards != 990 {
+		t.Fatalf("Expected all 990 turns and rivers to be enumerated but got %+v", exact)
+	}
+	// the aces need one of the last two aces without the last king (85 of the 990 turns and rivers)
+	if !near(exact.Equity[0], 100*85.0/990, 1e-9) || !near(exact.Equity[0]+exact.Equity[1], 100, 1e-9) {
+		t.Fatalf("Expected aces to have %f%% equity against a set of kings but got %+v", 100*85.0/990, exact)
+	}
+
+	// preflop there are too many boards so they are sampled, the same way every time
+	preflop := &Args{Hands: hands, Samples: 200000, Seed: 40}
+	res := mustCalculate(t, preflop)
+	if res.Exact || res.Boards != 200000 || !near(res.Equity[0], 81.26, 0.5) {
+		t.Fatalf("Expected aces to have about 81.26%% equity against kings but got %+v", res)
+	}
+	preflop.Workers = 1
+	if again := mustCalculate(t, preflop); again.Equity[0] != res.Equity[0] || again.Win[1] != res.Win[1] {
+		t.Fatalf("Expected the same seed to give the same answer on one core but got %+v and %+v", res, again)
+	}
+}
+
+func TestEquityForManyPlayers(t *testing.T) {
+	hands := make([]poker.CardSet, 0, MAX_PLAYERS)
+	deck := poker.AllCards.Cards()
+	for i := 0; i < MAX_PLAYERS; i++ {
+		hands = append(hands, deck[i]|deck[51-i])
+	}
+	res := mustCalculate(t, &Args{Hands: hands, Board: deck[20] | deck[21] | deck[22] | deck[23], Dead: deck[24]})
+	total := 0.0
+	for _, e := range res.Equity {
+		total += e
+	}
+	if !res.Exact || res.Boards != 27 || !near(total, 100, 1e-9) {
+		t.Fatalf("Expected the equities of every river to add up to 100 but got %+v", res)
+	}
+}
+
+func TestEquityAgainstARange(t *testing.T) {
+	aces := poker.AceOfSpades | poker.AceOfHearts
+	board := poker.TwoOfClubs | poker.SevenOfDiamonds | poker.KingOfHearts
+	// the king on the board leaves three combos of kings, which all make a set against the aces
+	kings := mustParseRange(t, "KK")
+	res := mustCalculate(t, &Args{Hands: []poker.CardSet{aces, poker.NoCards}, Ranges: []Range{nil, kings}, Board: board})
+	if !res.Exact || res.Boards != 3*990 || !near(res.Equity[0], 100*85.0/990, 1e-9) {
+		t.Fatalf("Expected aces to have %f%% equity against every set of kings but got %+v", 100*85.0/990, res)
+	}
+
+	// a weighted range is worth the weighted average of its combos
+	hand := poker.AceOfClubs | poker.KingOfClubs
+	r := mustParseRange(t, "AA, QJs:0.5")
+	res = mustCalculate(t, &Args{Hands: []poker.CardSet{hand, poker.NoCards}, Ranges: []Range{nil, r}, Board: board})
+	expected, weights := 0.0, 0.0
+	for combo, weight := range r.Remove(hand | board) {
+		known := mustCalculate(t, &Args{Hands: []poker.CardSet{hand, combo}, Board: board})
+		expected += weight * known.Equity[0]
+		weights += weight
+	}
+	if !res.Exact || weights != 3+2 || !near(res.Equity[0], expected/weights, 1e-9) {
+		t.Fatalf("Expected %f%% equity against three aces and half of four QJs but got %+v", expected/weights, res)
+	}
+}
+
+func TestEquityBetweenRanges(t *testing.T) {
+	// two aces that can not share a card always split the pot
+	aces := mustParseRange(t, "AA")
+	board := poker.TwoOfClubs | poker.ThreeOfDiamonds | poker.SevenOfHearts | poker.NineOfSpades | poker.JackOfClubs
+	res := mustCalculate(t, &Args{Hands: make([]poker.CardSet, 2), Ranges: []Range{aces, aces}, Board: board})
+	if !res.Exact || res.Boards != 6 || res.Equity[0] != 50 || res.Tie[1] != 100 {
+		t.Fatalf("Expected the six deals of aces against aces to split the pot but got %+v", res)
+	}
+
+	var tests = []*Args{
+		{Hands: make([]poker.CardSet, 2), Ranges: []Range{aces, aces}, Board: poker.AceOfHearts},
+		{Hands: make([]poker.CardSet, 2), Ranges: []Range{aces, aces, aces}},
+		{Hands: []poker.CardSet{poker.KingOfClubs | poker.KingOfDiamonds, 0}, Ranges: []Range{nil, mustParseRange(t, "AsAh")}, Dead: poker.AceOfSpades},
+		{Hands: []poker.CardSet{poker.KingOfClubs | poker.KingOfDiamonds, poker.QueenOfClubs | poker.QueenOfDiamonds}, Ranges: []Range{nil, aces}},
+		{Hands: []poker.CardSet{poker.KingOfClubs | poker.KingOfDiamonds, 0}, Ranges: []Range{nil, {poker.AceOfClubs: 1}}},
+	}
+	for _, args := range tests {
+		if _, err := Calculate(args); err == nil {
+			t.Errorf("Expected an error for ranges %v on board %s", args.Ranges, poker.CardSetToString(args.Board))
+		}
+	}
+}
+
+func TestEquitySamplesRangesByWeight(t *testing.T) {
+	aces := poker.AceOfSpades | poker.AceOfHearts
+	kings := poker.KingOfClubs | poker.KingOfDiamonds
+	queens := poker.QueenOfClubs | poker.QueenOfDiamonds
+	against := func(hand poker.CardSet) float64 {
+		return mustCalculate(t, &Args{Hands: []poker.CardSet{aces, hand}, Samples: 200000, Seed: 42}).Equity[0]
+	}
+	expected := (against(kings) + 0.5*against(queens)) / 1.5
+
+	args := &Args{Hands: []poker.CardSet{aces, poker.NoCards}, Ranges: []Range{nil, {kings: 1, queens: 0.5}}, Samples: 200000, Seed: 42}
+	res := mustCalculate(t, args)
+	if res.Exact || res.Boards != 200000 || !near(res.Equity[0], expected, 0.5) {
+		t.Fatalf("Expected aces to have about %f%% equity against the range but got %+v", expected, res)
+	}
+	args.Workers = 1
+	if again := mustCalculate(t, args); again.Equity[0] != res.Equity[0] {
+		t.Fatalf("Expected the same seed to give the same answer on one core but got %+v and %+v", res, again)
+	}
+}
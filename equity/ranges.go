@@ -0,0 +1,346 @@
+package equity
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/4gatepylon/GoPoker/poker"
+)
+
+// Ranges are written in the usual shorthand, as a comma separated list of:
+//   - pairs (TT), suited (AKs), offsuit (AKo) or both (AK) hands
+//   - every pair from one up (TT+) or between two (77-55)
+//   - every kicker from one up to just under the top card (A2s+, K9o+) or between two (A5s-A2s)
+//   - connectors moving up together to aces (76s+, KQo+) or between two (T9s-65s)
+//   - single combos (AsKs)
+// where any of them can be given a weight after a colon (AA:0.5) saying how often the range holds
+// them. Hands are read in either case, the way poker.ParseHandClass reads them. Ranges are printed in a canonical form: pairs, then suited and then offsuit hands by the top
+// card, with runs of pairs, kickers and connectors joined up, and weights other than one after
+// everything else.
+
+// Two hole cards and how often they are in a range (more than zero and at most one)
+type Range map[poker.CardSet]float64
+
+// A hand class (i.e. AKs) or, if both is set, a suited class along with its offsuit one (AK, which
+// is kept as the offsuit class)
+type class struct {
+	poker.HandClass
+	both bool
+}
+
+// return the pair of a value (2 through 14)
+func pair(value uint64) class {
+	return class{HandClass: poker.HandClass{High: value, Low: value}}
+}
+
+// return the class of two other values that is the same kind of hand (suited, offsuit or both)
+func (c class) with(high uint64, low uint64) class {
+	return class{poker.HandClass{High: high, Low: low, Suited: c.Suited}, c.both}
+}
+
+func (c class) isPair() bool {
+	return c.High == c.Low
+}
+
+// Classes are written as poker.ParseHandClass reads them (in either case), and two values that are
+// not a pair without an s or an o stand for both
+func parseClass(s string) (class, error) {
+	c, err := poker.ParseHandClass(s)
+	if err == nil {
+		return class{HandClass: c}, nil
+	}
+	if both, bothErr := poker.ParseHandClass(s + "o"); len(s) == 2 && bothErr == nil {
+		return class{both, true}, nil
+	}
+	return class{}, err
+}
+
+func (c class) combos() []poker.CardSet {
+	if c.both {
+		suited := c.HandClass
+		suited.Suited = true
+		return append(suited.Combos(), c.HandClass.Combos()...)
+	}
+	return c.HandClass.Combos()
+}
+
+// Parse a range entry (without its weight) into every class it stands for
+func parseEntry(entry string) ([]class, error) {
+	if parts := strings.Split(entry, "-"); len(parts) == 2 {
+		top, err := parseClass(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		bottom, err := parseClass(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		if top.Suited != bottom.Suited || top.both != bottom.both || top.isPair() != bottom.isPair() {
+			return nil, fmt.Errorf("Both ends of %q should be the same kind of hand", entry)
+		}
+		if top.Low < bottom.Low {
+			top, bottom = bottom, top
+		}
+		classes := make([]class, 0, 13)
+		switch {
+		case top.isPair():
+			for r := bottom.Low; r <= top.Low; r++ {
+				classes = append(classes, pair(r))
+			}
+		case top.High == bottom.High:
+			for r := bottom.Low; r <= top.Low; r++ {
+				classes = append(classes, top.with(top.High, r))
+			}
+		case top.High-top.Low == bottom.High-bottom.Low:
+			for r := bottom.Low; r <= top.Low; r++ {
+				classes = append(classes, top.with(r+top.High-top.Low, r))
+			}
+		default:
+			return nil, fmt.Errorf("The ends of %q should share a top card or a gap", entry)
+		}
+		return classes, nil
+	}
+	if strings.HasSuffix(entry, "+") {
+		c, err := parseClass(strings.TrimSuffix(entry, "+"))
+		if err != nil {
+			return nil, err
+		}
+		classes := make([]class, 0, 13)
+		switch {
+		case c.isPair():
+			for r := c.Low; r <= 14; r++ {
+				classes = append(classes, pair(r))
+			}
+		case c.High-c.Low == 1:
+			// the kicker can not go any higher so both cards do
+			for r := c.Low; r < 14; r++ {
+				classes = append(classes, c.with(r+1, r))
+			}
+		default:
+			for r := c.Low; r < c.High; r++ {
+				classes = append(classes, c.with(c.High, r))
+			}
+		}
+		return classes, nil
+	}
+	c, err := parseClass(entry)
+	if err != nil {
+		return nil, err
+	}
+	return []class{c}, nil
+}
+
+// Parse a single combo (i.e. AsKs or A♠K♠)
+func parseCombo(s string) (poker.CardSet, bool) {
+	combo, err := poker.ParseCardSet(s)
+	return combo, err == nil && combo.Count() == 2
+}
+
+// Parse a range (i.e. "TT+, AKs, A5s-A2s, KQo, 76s+, AA:0.5"); hands listed more than once
+// keep the weight they were given last
+func ParseRange(s string) (Range, error) {
+	r := Range{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		weight := 1.0
+		if i := strings.IndexByte(entry, ':'); i >= 0 {
+			w, err := strconv.ParseFloat(entry[i+1:], 64)
+			// a weight of zero takes the hands out of the range
+			if err != nil || w != 0 && (math.IsNaN(w) || !(w > 0 && w <= 1)) {
+				return nil, fmt.Errorf("The weight of %q should be between zero and one", entry)
+			}
+			entry, weight = entry[:i], w
+		}
+		cs := make([]poker.CardSet, 0, 12)
+		if combo, ok := parseCombo(entry); ok {
+			cs = append(cs, combo)
+		} else {
+			classes, err := parseEntry(entry)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range classes {
+				cs = append(cs, c.combos()...)
+			}
+		}
+		for _, combo := range cs {
+			if weight == 0 {
+				delete(r, combo)
+			} else {
+				r[combo] = weight
+			}
+		}
+	}
+	return r, nil
+}
+
+// Return the range without the combos that share a card with the known cards
+func (r Range) Remove(known poker.CardSet) Range {
+	left := Range{}
+	for combo, weight := range r {
+		if combo&known == 0 {
+			left[combo] = weight
+		}
+	}
+	return left
+}
+
+// Return the combos of the range in a fixed order (from the highest)
+func (r Range) Combos() []poker.CardSet {
+	cs := make([]poker.CardSet, 0, len(r))
+	for combo := range r {
+		cs = append(cs, combo)
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i] > cs[j] })
+	return cs
+}
+
+// Return how many combos are in the range counting each by its weight
+func (r Range) Size() float64 {
+	size := 0.0
+	for _, weight := range r {
+		size += weight
+	}
+	return size
+}
+
+func (c class) String() string {
+	if c.both {
+		return strings.TrimSuffix(c.HandClass.String(), "o")
+	}
+	return c.HandClass.String()
+}
+
+func comboString(combo poker.CardSet) string {
+	s := ""
+	// the higher card first with its suit in lower case (i.e. AsKs)
+	for _, c := range strings.Fields(poker.CardSetToString(combo)) {
+		s = c[:len(c)-1] + strings.ToLower(c[len(c)-1:]) + s
+	}
+	return s
+}
+
+// A run of classes written as a single entry, by its highest class
+type run struct {
+	first class
+	last  class
+	entry string
+}
+
+// the next class down with the same top card (AQs after AKs) and with the same gap (KQs after AKs)
+func nextKicker(c class) class    { return c.with(c.High, c.Low-1) }
+func nextConnector(c class) class { return c.with(c.High-1, c.Low-1) }
+
+// Join classes (from the highest) into runs where each class is next(the one before), written as
+// a single class, the lowest class and a plus if the run starts at the top, or else the ends of the run
+func findRuns(classes []class, next func(class) class, top func(class) bool) []run {
+	runs := make([]run, 0, len(classes))
+	for i := 0; i < len(classes); {
+		j := i
+		for j+1 < len(classes) && classes[j+1] == next(classes[j]) {
+			j++
+		}
+		first, last := classes[i], classes[j]
+		entry := first.String() + "-" + last.String()
+		switch {
+		case i == j:
+			entry = first.String()
+		case top(first):
+			entry = last.String() + "+"
+		}
+		runs = append(runs, run{first, last, entry})
+		i = j + 1
+	}
+	return runs
+}
+
+// Print the range in its canonical form
+func (r Range) String() string {
+	weights := make([]float64, 0, 1)
+	byWeight := map[float64]Range{}
+	for combo, weight := range r {
+		if _, ok := byWeight[weight]; !ok {
+			byWeight[weight] = Range{}
+			weights = append(weights, weight)
+		}
+		byWeight[weight][combo] = weight
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(weights)))
+
+	entries := make([]string, 0)
+	for _, weight := range weights {
+		group := byWeight[weight]
+		written := make([]string, 0)
+		// whole classes first and whatever is left as single combos
+		covered := map[poker.CardSet]bool{}
+		whole := func(c class) bool {
+			cs := c.combos()
+			for _, combo := range cs {
+				if _, ok := group[combo]; !ok {
+					return false
+				}
+			}
+			for _, combo := range cs {
+				covered[combo] = true
+			}
+			return true
+		}
+		var pairs []class
+		for rank := uint64(14); rank >= 2; rank-- {
+			if c := pair(rank); whole(c) {
+				pairs = append(pairs, c)
+			}
+		}
+		for _, pr := range findRuns(pairs, nextConnector, func(c class) bool { return c.High == 14 }) {
+			written = append(written, pr.entry)
+		}
+		for _, kind := range []class{{HandClass: poker.HandClass{Suited: true}}, {}} {
+			// runs of kickers first and then runs of connectors out of the classes left on their own
+			var runs []run
+			var byGap [13][]class
+			for high := uint64(14); high >= 3; high-- {
+				var kickers []class
+				for low := high - 1; low >= 2; low-- {
+					if c := kind.with(high, low); whole(c) {
+						kickers = append(kickers, c)
+					}
+				}
+				for _, kr := range findRuns(kickers, nextKicker, func(c class) bool { return c.Low == c.High-1 }) {
+					if gap := kr.first.High - kr.first.Low; kr.first == kr.last {
+						byGap[gap] = append(byGap[gap], kr.first)
+					} else {
+						runs = append(runs, kr)
+					}
+				}
+			}
+			for _, classes := range byGap {
+				runs = append(runs, findRuns(classes, nextConnector, func(c class) bool { return c.High == 14 && c.Low == 13 })...)
+			}
+			sort.Slice(runs, func(i, j int) bool {
+				a, b := runs[i].first, runs[j].first
+				return a.High > b.High || a.High == b.High && a.Low > b.Low
+			})
+			for _, sr := range runs {
+				written = append(written, sr.entry)
+			}
+		}
+		for _, combo := range group.Combos() {
+			if !covered[combo] {
+				written = append(written, comboString(combo))
+			}
+		}
+		for _, entry := range written {
+			if weight != 1 {
+				entry += ":" + strconv.FormatFloat(weight, 'g', -1, 64)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return strings.Join(entries, ", ")
+}
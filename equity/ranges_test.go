@@ -0,0 +1,151 @@
+package equity
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/4gatepylon/GoPoker/poker"
+)
+
+func mustParseRange(t *testing.T, s string) Range {
+	r, err := ParseRange(s)
+	if err != nil {
+		t.Fatalf("Failed to parse range %q: `%v`", s, err)
+	}
+	return r
+}
+
+func TestParseRangeCountsCombos(t *testing.T) {
+	var tests = []struct {
+		text   string
+		combos int
+	}{
+		{"AA", 6},
+		{"AKs", 4},
+		{"KQo", 12},
+		{"AK", 16},
+		{"TT+", 30},
+		{"77-55", 18},
+		{"A5s-A2s", 16},
+		{"A2s+", 48},
+		{"K9o+", 48},
+		{"76s+", 32},
+		{"KQs+", 8},
+		{"T9s-65s", 20},
+		{"AsKs", 1},
+		{"A♠K♠, 10s9s", 2},
+		{"TT+, AKs, A5s-A2s, KQo, 76s+", 30 + 4 + 16 + 12 + 32 - 4},
+		{"", 0},
+	}
+	for _, test := range tests {
+		if r := mustParseRange(t, test.text); len(r) != test.combos {
+			t.Errorf("Expected %d combos in %q but got %d", test.combos, test.text, len(r))
+		}
+	}
+	for _, bad := range []string{"A", "AAs", "AKx", "XK", "AKs-Q9s", "AKs-A5o", "TT-A5s", "AA:2", "AA:x", "AA:NaN", "AA:-0.5", "AA:+Inf", "AsAs"} {
+		if _, err := ParseRange(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestParseRangeIgnoresCase(t *testing.T) {
+	for _, text := range []string{"aks, tt+, a5s-a2s, kqo, ak", "AKS, TT+, A5S-A2S, KQO, AK"} {
+		if s := mustParseRange(t, text).String(); s != "TT+, AKs, A5s-A2s, KQo+" {
+			t.Errorf("Expected %q to be read like its upper case but got %q", text, s)
+		}
+		// and every class in it is read the same way on its own
+		for _, entry := range []string{"aks", "kqo", "tt"} {
+			c, err := poker.ParseHandClass(entry)
+			if r := mustParseRange(t, entry); err != nil || len(r) != len(c.Combos()) {
+				t.Errorf("Expected %q to be the same class in a range: `%v`", entry, err)
+			}
+		}
+	}
+}
+
+func TestParseRangeWeights(t *testing.T) {
+	r := mustParseRange(t, "QQ+, AA:0.5, KK:0, 22:-0")
+	if len(r) != 12 || r.Size() != 9 {
+		t.Fatalf("Expected half the aces and all the queens but got %d combos (%v)", len(r), r.Size())
+	}
+	if w := r[poker.AceOfSpades|poker.AceOfHearts]; w != 0.5 {
+		t.Fatalf("Expected AsAh to have weight 0.5 but got %v", w)
+	}
+	if _, ok := r[poker.KingOfSpades|poker.KingOfHearts]; ok {
+		t.Fatalf("Expected kings to be taken out of the range")
+	}
+}
+
+func TestRangeRemovesBlockedCombos(t *testing.T) {
+	r := mustParseRange(t, "AA, AKs").Remove(poker.AceOfSpades | poker.KingOfHearts)
+	if len(r) != 3+2 {
+		t.Fatalf("Expected three aces and two suited AK left but got %s", r)
+	}
+	for combo := range r {
+		if combo&(poker.AceOfSpades|poker.KingOfHearts) != 0 {
+			t.Fatalf("Expected %s to be blocked", poker.CardSetToString(combo))
+		}
+	}
+	if s := r.String(); s != "AhAd, AhAc, AdAc, AdKd, AcKc" {
+		t.Fatalf("Expected the combos left to be printed one by one but got %q", s)
+	}
+}
+
+func TestRangeStringIsCanonical(t *testing.T) {
+	var tests = []struct {
+		text      string
+		canonical string
+	}{
+		{"AA", "AA"},
+		{"QQ, AA, KK", "QQ+"},
+		{"55-77, 99", "99, 77-55"},
+		{"A2s+", "A2s+"},
+		{"A5s-A2s", "A5s-A2s"},
+		{"AKo, AK", "AKs, AKo"},
+		{"KQo, K9o+", "K9o+"},
+		{"76s+", "76s+"},
+		{"KQo+", "KQo+"},
+		{"T9s-65s", "T9s-65s"},
+		{"A2s+, 76s+", "A2s+, KQs-76s"},
+		{"AJo, KTo, Q9o, 54s, 43s", "54s-43s, AJo-Q9o"},
+		{"AKs, AQs, KQs, QJs, 87s", "AQs+, KQs-QJs, 87s"},
+		{"TT+, AKs, AA:0.5, 22:0.25", "KK-TT, AKs, AA:0.5, 22:0.25"},
+		{"AsKs, AhKh", "AsKs, AhKh"},
+	}
+	for _, test := range tests {
+		r := mustParseRange(t, test.text)
+		s := r.String()
+		if s != test.canonical {
+			t.Errorf("Expected %q to be printed as %q but got %q", test.text, test.canonical, s)
+		}
+		again := mustParseRange(t, s)
+		if len(again) != len(r) || again.String() != s {
+			t.Errorf("Expected %q to parse back into the same range but got %q", s, again.String())
+		}
+	}
+}
+
+func TestRangeStringRoundTrips(t *testing.T) {
+	rng := rand.New(rand.NewSource(41))
+	for i := 0; i < 500; i++ {
+		r := Range{}
+		for n := 0; n < poker.HAND_CLASSES; n++ {
+			if rng.Intn(4) > 0 {
+				continue
+			}
+			for _, combo := range poker.HandClassAt(n).Combos() {
+				r[combo] = 1
+			}
+		}
+		again := mustParseRange(t, r.String())
+		if len(again) != len(r) {
+			t.Fatalf("Expected %q to parse back into %d combos but got %d", r.String(), len(r), len(again))
+		}
+		for combo := range r {
+			if _, ok := again[combo]; !ok {
+				t.Fatalf("Expected %q to hold %s", r.String(), comboString(combo))
+			}
+		}
+	}
+}
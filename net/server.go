@@ -2,18 +2,37 @@ package net
 
 import (
 	"context"
+	crand "crypto/rand"
+	"encoding/binary"
 	"log"
 	"net"
 	"fmt"
 	"io"
+	"sync"
 
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
 	"github.com/4gatepylon/GoPoker/protocol"
 	"github.com/4gatepylon/GoPoker/poker"
+	"github.com/4gatepylon/GoPoker/utils"
 
 	pb "github.com/4gatepylon/GoPoker/net/proto"
 )
 
+// Every stream code belongs to a single player in a single game
+type streamPlayer struct {
+	game      poker.GameLike
+	name      string
+	spectator bool
+	stream    pb.GameServer_GameStreamServer // Open stream to push updates on (nil if there is none)
+}
+
+// A seat at a game, by game name and player name
+type seatKey struct {
+	game string
+	name string
+}
+
 // Server implement both protocol.ServerLike and protocol.NetServerLike
 type Server struct {
 	pb.UnimplementedGameServerServer
@@ -21,33 +40,370 @@ type Server struct {
 	responses         chan *protocol.NetResponse
 	Addr              string
 	running           bool
-	games             map[uint64]poker.GameLike
+	games             map[string]poker.GameLike  // game name => game
+	streams           map[uint64]*streamPlayer   // stream code => player on that stream
+	rejoinCodes       map[seatKey]uint64         // seat => code it takes to resume it while away
+	mu                sync.Mutex                 // Guards games, streams and rejoin codes (games are not thread safe)
 	grpcServer        *grpc.Server
 }
 
+// Hand out a new stream code for a player (old codes for the same player stay valid until
+// their stream drops)
+func (server *Server) newStream(game poker.GameLike, name string, spectator bool) uint64 {
+	code := utils.RandInt64()
+	for _, taken := server.streams[code]; taken; _, taken = server.streams[code] {
+		code = utils.RandInt64()
+	}
+	server.streams[code] = &streamPlayer{game: game, name: name, spectator: spectator}
+	return code
+}
+
+// Push a game mode update (along with the rest of the game's state) to everyone at a game who
+// has a stream open. Games announce a new mode while the server holds mu.
+func (server *Server) pushMode(game poker.GameLike, to []string) {
+	names := make(map[string]bool, len(to))
+	for _, name := range to {
+		names[name] = true
+	}
+	for _, player := range server.streams {
+		if player.game != game || player.stream == nil || !names[player.name] {
+			continue
+		}
+		err := player.stream.Send(&pb.GameResponse{
+			Info:       gameInfo(game, player.name, player.spectator),
+			UpdateType: proto.Uint64(protocol.UI_RPTYPE_GAME_GMODE_UPDATE),
+		})
+		if err != nil {
+			log.Printf("Failed to send the new game mode to %s: `%v`\n", player.name, err)
+		}
+	}
+}
+
+// Hand out the code a player needs to take their seat back after their stream drops. It is only
+// given to whoever took the seat, so unlike stream codes it has to be impossible to guess.
+func (server *Server) newRejoinCode(game string, name string) (uint64, error) {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return 0, fmt.Errorf("Failed to make a rejoin code for %s: `%v`", name, err)
+	}
+	code := binary.LittleEndian.Uint64(b[:])
+	server.rejoinCodes[seatKey{game: game, name: name}] = code
+	return code, nil
+}
+
+// Whether a player is waiting for a seat at a game rather than sitting in one
+func waitlisted(game poker.GameLike, name string) bool {
+	for _, entry := range game.Waitlist() {
+		if entry.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Forget a player who is no longer at a game: their streams and the code to take back their seat
+func (server *Server) forget(game poker.GameLike, name string) {
+	for code, player := range server.streams {
+		if player.game == game && player.name == name && !player.spectator {
+			delete(server.streams, code)
+		}
+	}
+	for key := range server.rejoinCodes {
+		if key.name == name && server.games[key.game] == game {
+			delete(server.rejoinCodes, key)
+		}
+	}
+}
+
+// The state of a game as seen by a single player (who can only see their own cards)
+// or by a spectator (who sees what the rail is allowed to see)
+func gameInfo(game poker.GameLike, name string, spectator bool) *pb.GameInfo {
+	info := &pb.GameInfo{Middle: &pb.MiddleInfo{Pots: game.Pots()}}
+	for _, c := range game.Middle() {
+		info.Middle.Middle = append(info.Middle.Middle, cardBits(c))
+	}
+	players := game.Players()
+	if spectator {
+		players = game.SpectatorPlayers()
+		info.RailChat = game.RailChat()
+	}
+	info.RunItOffer = game.RunItOffer()
+	info.GameMode = proto.Uint64(game.Mode())
+	for _, p := range players {
+		player := &pb.PlayerInfo{
+			Name:       p.Name,
+			Chips:      p.Chips,
+			PlayerPot:  p.Bet,
+			IsAdmin:    proto.Bool(p.Mod),
+			Away:       proto.Bool(p.Away),
+			SittingOut: proto.Bool(p.SittingOut),
+			OwesBlinds: proto.Bool(p.OwesBlinds),
+		}
+		if p.Name == name || spectator {
+			player.LeftCard = proto.Uint64(cardBits(p.Cards[0]))
+			player.RightCard = proto.Uint64(cardBits(p.Cards[1]))
+			for _, c := range p.Cards {
+				player.HoleCards = append(player.HoleCards, cardBits(c))
+			}
+		} else if faceUp(p) {
+			// Everyone sees the cards dealt face up and none of the ones dealt face down
+			for i, c := range p.Cards {
+				if p.Up[i] {
+					player.HoleCards = append(player.HoleCards, cardBits(c))
+				} else {
+					player.HoleCards = append(player.HoleCards, 0)
+				}
+			}
+		}
+		info.Players = append(info.Players, player)
+	}
+	for _, e := range game.Waitlist() {
+		info.Waitlist = append(info.Waitlist, &pb.WaitlistInfo{Name: e.Name, SeatOffered: e.Offered})
+	}
+	return info
+}
+
+// Whether any of a player's cards were dealt face up
+func faceUp(p *poker.PlayerInfo) bool {
+	for _, up := range p.Up {
+		if up {
+			return true
+		}
+	}
+	return false
+}
+
+func cardBits(card poker.CardLike) uint64 {
+	if c, ok := card.(poker.Card); ok {
+		return uint64(c)
+	}
+	return 0
+}
+
+// Joining a game as a player who is away (i.e. whose stream dropped) with the rejoin code they were
+// given when they first joined resumes their seat and hands back the current state of the game
+// along with a new stream code. Without the code the name is taken like any other.
 func (server *Server) JoinGame(ctx context.Context, joinReq *pb.JoinGameRequest) (*pb.JoinGameResponse, error) {
-	// TODO recieve a request, send some back and forth on the channel, then send a response
-	return nil, nil // this is basically a rest request, we will ignore the channels here
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	game, found := server.games[joinReq.Name]
+	if !found {
+		return &pb.JoinGameResponse{JoinSuccessful: false}, nil
+	}
+	name := joinReq.PlayerName
+	if joinReq.GetSpectate() {
+		added, joined, err := game.AddSpectator(&name, joinReq.JoinCode)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to add spectator %s to game %s: `%v`", name, joinReq.Name, err)
+		}
+		if !joined {
+			return &pb.JoinGameResponse{JoinSuccessful: false}, nil
+		}
+		return &pb.JoinGameResponse{
+			JoinSuccessful: true,
+			StreamCode:     proto.Uint64(server.newStream(game, *added, true)),
+			GameInfo:       gameInfo(game, *added, true),
+		}, nil
+	}
+	code, held := server.rejoinCodes[seatKey{game: joinReq.Name, name: name}]
+	if held && joinReq.RejoinCode != nil && *joinReq.RejoinCode == code {
+		back, err := game.Reconnect(&name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to return player %s to game %s: `%v`", name, joinReq.Name, err)
+		}
+		if back {
+			return &pb.JoinGameResponse{
+				JoinSuccessful: true,
+				StreamCode:     proto.Uint64(server.newStream(game, name, false)),
+				GameInfo:       gameInfo(game, name, false),
+				RejoinCode:     proto.Uint64(code),
+			}, nil
+		}
+	}
+	added, joined, err := game.AddPlayer(&name, joinReq.JoinCode)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to add player %s to game %s: `%v`", name, joinReq.Name, err)
+	}
+	if added == nil {
+		return &pb.JoinGameResponse{JoinSuccessful: false}, nil
+	}
+	code, err = server.newRejoinCode(joinReq.Name, *added)
+	if err != nil {
+		return nil, err
+	}
+	if !joined {
+		return &pb.JoinGameResponse{
+			JoinSuccessful:   false,
+			StreamCode:       proto.Uint64(server.newStream(game, *added, false)),
+			GameInfo:         gameInfo(game, *added, false),
+			WaitlistPosition: proto.Uint64(uint64(len(game.Waitlist()))),
+			RejoinCode:       proto.Uint64(code),
+		}, nil
+	}
+	return &pb.JoinGameResponse{
+		JoinSuccessful: true,
+		StreamCode:     proto.Uint64(server.newStream(game, *added, false)),
+		GameInfo:       gameInfo(game, *added, false),
+		RejoinCode:     proto.Uint64(code),
+	}, nil
 }
 func (server *Server) CreateGame(ctx context.Context, createReq *pb.CreateGameRequest) (*pb.CreateGameResponse, error) {
-	// TODO same as above
-	return nil, nil // this is basically a rest request, we will ignore the channels here
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	name := createReq.GetName()
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", utils.RandAdjAnimal(nil), utils.RandString(3))
+	}
+	if _, taken := server.games[name]; taken {
+		return &pb.CreateGameResponse{CreateSuccessful: proto.Bool(false)}, nil
+	}
+	creator, game, err := poker.New(&createReq.PlayerName, &poker.GameInitArgs{
+		Name:     &name,
+		JoinCode: createReq.JoinCode,
+		Public:   !createReq.GetPrivate(),
+		Mode:     createReq.GetMode(),
+
+		Games:         createReq.Games,
+		HandsPerGame:  createReq.GetHandsPerGame(),
+		DealersChoice: createReq.GetDealersChoice(),
+
+		Language: createReq.GetLanguage(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create game %s: `%v`", name, err)
+	}
+	code, err := server.newRejoinCode(name, *creator)
+	if err != nil {
+		return nil, err
+	}
+	server.games[name] = game
+	game.OnModeChange(func(to []string, mode uint64) {
+		server.pushMode(game, to)
+	})
+	return &pb.CreateGameResponse{
+		StreamCode:       server.newStream(game, *creator, false),
+		CreateSuccessful: proto.Bool(true),
+		RejoinCode:       proto.Uint64(code),
+	}, nil
 }
+// Leaving in the middle of a round folds the player's hand; the rest of their stack is cashed out
 func (server *Server) LeaveGame(ctx context.Context, leaveReq *pb.LeaveGameRequest) (*pb.LeaveGameResponse, error) {
-	// TODO ibid
-	return nil, nil // this is basically a rest request, we will ignore the channels here
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	player, found := server.streams[leaveReq.StreamCode]
+	if !found || player.name != leaveReq.PlayerName {
+		return &pb.LeaveGameResponse{ResultMessage: proto.String("Unknown stream code")}, nil
+	}
+	if player.spectator {
+		player.game.RemoveSpectator(&player.name)
+		delete(server.streams, leaveReq.StreamCode)
+		return &pb.LeaveGameResponse{ResultMessage: proto.String(fmt.Sprintf("%s left the rail", player.name))}, nil
+	}
+	// Players on the waitlist have no seat to leave
+	if waitlisted(player.game, player.name) {
+		if _, err := player.game.LeaveWaitlist(&player.name); err != nil {
+			return nil, fmt.Errorf("Failed to take %s off the waitlist: `%v`", player.name, err)
+		}
+		server.forget(player.game, player.name)
+		return &pb.LeaveGameResponse{ResultMessage: proto.String(fmt.Sprintf("%s left the waitlist", player.name))}, nil
+	}
+	left, err := player.game.LeavePlayer(&player.name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to remove %s: `%v`", player.name, err)
+	}
+	delete(server.streams, leaveReq.StreamCode)
+	for key := range server.rejoinCodes {
+		if key.name == player.name && server.games[key.game] == player.game {
+			delete(server.rejoinCodes, key)
+		}
+	}
+	if !left {
+		return &pb.LeaveGameResponse{ResultMessage: proto.String("Already left")}, nil
+	}
+	cashOuts := player.game.CashOuts()
+	cashOut := cashOuts[len(cashOuts)-1]
+	return &pb.LeaveGameResponse{ResultMessage: proto.String(fmt.Sprintf("%s left with %d chips", cashOut.Name, cashOut.Chips))}, nil
 }
 func (server *Server) GameStream(stream pb.GameServer_GameStreamServer) error {
 	// Handle incoming requests from the network and issue them on the channel
 	go func() {
+		var player *streamPlayer
 		for {
-			_, err := stream.Recv()
+			in, err := stream.Recv()
 			if err == io.EOF {
 				log.Printf("Client closed the connection\n")
-				return
 			}
 			if err != nil {
-				return // TODO
+				// The player keeps their seat and stack for a while so they can rejoin, but a
+				// player on the waitlist could not hear a seat being offered so they lose their place
+				if player != nil {
+					server.mu.Lock()
+					player.stream = nil
+					if player.spectator {
+						player.game.RemoveSpectator(&player.name)
+					} else if waitlisted(player.game, player.name) {
+						if _, err := player.game.LeaveWaitlist(&player.name); err != nil {
+							log.Printf("Failed to take %s off the waitlist: `%v`\n", player.name, err)
+						}
+						server.forget(player.game, player.name)
+					} else if _, err := player.game.Disconnect(&player.name); err != nil {
+						log.Printf("Failed to mark %s as away: `%v`\n", player.name, err)
+					}
+					server.mu.Unlock()
+				}
+				return
+			}
+			// The first message on a stream tells us which player it belongs to
+			if player == nil {
+				server.mu.Lock()
+				player = server.streams[in.StreamCode]
+				if player != nil {
+					player.stream = stream
+					err = stream.Send(&pb.GameResponse{Info: gameInfo(player.game, player.name, player.spectator)})
+				}
+				server.mu.Unlock()
+				if player == nil {
+					stream.Send(&pb.GameResponse{ChangeStreamCode: pb.StreamControl_STREAM_ENDED_INTERNAL.Enum()})
+					return
+				}
+				if err != nil {
+					log.Printf("Failed to send game state to %s: `%v`\n", player.name, err)
+				}
+			}
+			for _, action := range in.Action {
+				server.mu.Lock()
+				switch {
+				case action == pb.ActionType_ACCEPT_SEAT:
+					if _, err := player.game.AcceptSeat(&player.name); err != nil {
+						log.Printf("Failed to seat %s: `%v`\n", player.name, err)
+					} else {
+						player.spectator = false
+					}
+				case action == pb.ActionType_SIT_IN || action == pb.ActionType_SIT_IN_WAIT_FOR_BB:
+					if _, err := player.game.SitIn(&player.name, action == pb.ActionType_SIT_IN); err != nil {
+						log.Printf("Failed to sit %s in: `%v`\n", player.name, err)
+					}
+				case action == pb.ActionType_RUN_IT:
+					if _, err := player.game.AgreeRunIt(&player.name, in.GetRunItTimes()); err != nil {
+						log.Printf("Failed to run it %d times for %s: `%v`\n", in.GetRunItTimes(), player.name, err)
+					}
+				case action == pb.ActionType_CHOOSE_GAME:
+					if _, err := player.game.ChooseGame(&player.name, in.GetGameMode()); err != nil {
+						log.Printf("Failed to choose game %d for %s: `%v`\n", in.GetGameMode(), player.name, err)
+					}
+				case action == pb.ActionType_DRAW:
+					if _, err := player.game.Move(poker.MTYPE_DRAW, in.GetDiscards(), &player.name); err != nil {
+						log.Printf("Failed to draw for %s: `%v`\n", player.name, err)
+					}
+				case action == pb.ActionType_CHAT_MESSAGE && player.spectator:
+					if _, err := player.game.RailMessage(&player.name, in.Message); err != nil {
+						log.Printf("Failed to send %s's rail message: `%v`\n", player.name, err)
+					}
+				}
+				server.mu.Unlock()
 			}
 			// do translation TODO
 			server.requests <- &protocol.NetRequest{
@@ -131,13 +487,13 @@ func (server *Server) Stop() error {
 }
 
 func (server *Server) Teardown() error {
-	for stream_code, game := range server.games {
+	for name, game := range server.games {
 		// TODO send a "tearing down" message
 		close(server.requests)
 		close(server.responses)
 		err := game.Teardown()
 		if err != nil {
-			return fmt.Errorf("Failed to shut down game with stream code %d and err `%v`\n", stream_code, err)
+			return fmt.Errorf("Failed to shut down game %s with err `%v`\n", name, err)
 		}
 	}
 
@@ -170,7 +526,9 @@ func NewServer(addr string) (*Server, error) {
 		responses:         make(chan *protocol.NetResponse, 8),
 		Addr:              addr,
 		running:           true,
-		games:             make(map[uint64]poker.GameLike, 0),
+		games:             make(map[string]poker.GameLike, 0),
+		streams:           make(map[uint64]*streamPlayer, 0),
+		rejoinCodes:       make(map[seatKey]uint64, 0),
 	}
 
 	lis, err := net.Listen("tcp", serverAddr())
@@ -0,0 +1,170 @@
+package poker
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Before the flop only the values of the two hole cards and whether they share a suit matter, so
+// the 1326 combos of two cards fall into 169 classes: 13 pairs ("77", six combos each), 78 suited
+// hands ("AKs", four combos each) and 78 offsuit hands ("AKo", twelve combos each). Classes are
+// numbered the way a range chart is read, row by row from the aces in the top left corner, with the
+// pairs on the diagonal, suited hands above it and offsuit hands below it.
+//
+// After that what matters is which cards share a suit rather than which suits they are, so
+// swapping the suits around gives the same situation (AhKh on Qh 7h 2c plays like AsKs on Qs 7s 2d).
+// CanonicalSuits picks one of every group of such situations so that they can share a cache entry.
+
+const HAND_CLASSES = 169
+
+// One of the 169 classes of two hole cards
+type HandClass struct {
+	High   uint64 // The higher value (2 through 14)
+	Low    uint64 // The lower value (the same as High in pairs)
+	Suited bool   // Pairs are never suited
+}
+
+// values by how they are written in hand classes (i.e. T for ten)
+const classValues = "  23456789TJQKA"
+
+// return the suit of a single card (0 through 3, from clubs to spades)
+func cardSuit(card CardSet) uint {
+	return uint(bits.TrailingZeros64(uint64(card & ^_Ace1s))) % 4
+}
+
+// return the class of two hole cards
+func ClassOf(hole CardSet) (HandClass, error) {
+	if hole.Count() != 2 || hole&Jokers != 0 {
+		return HandClass{}, fmt.Errorf("Expected two hole cards but got %s", CardSetToString(hole))
+	}
+	cards := hole.Cards()
+	high, low := cardValue(cards[1]), cardValue(cards[0])
+	return HandClass{High: high, Low: low, Suited: high != low && cardSuit(cards[0]) == cardSuit(cards[1])}, nil
+}
+
+// return the class numbered i (from 0 for AA to 168 for 22)
+func HandClassAt(i int) HandClass {
+	row, col := uint64(i/13), uint64(i%13)
+	switch {
+	case row < col:
+		return HandClass{High: 14 - row, Low: 14 - col, Suited: true}
+	case row > col:
+		return HandClass{High: 14 - col, Low: 14 - row}
+	}
+	return HandClass{High: 14 - row, Low: 14 - row}
+}
+
+// return the number of the class (i.e. where it is in a range chart)
+func (c HandClass) Index() int {
+	row, col := 14-c.High, 14-c.Low
+	if !c.Suited {
+		row, col = col, row
+	}
+	return int(row*13 + col)
+}
+
+func (c HandClass) String() string {
+	s := string([]byte{classValues[c.High], classValues[c.Low]})
+	switch {
+	case c.High == c.Low:
+		return s
+	case c.Suited:
+		return s + "s"
+	}
+	return s + "o"
+}
+
+// return the class written as (for example) "AKs", "AKo" or "77" (in either case)
+func ParseHandClass(s string) (HandClass, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) != 2 && len(s) != 3 {
+		return HandClass{}, fmt.Errorf("Expected a hand class like AKs, AKo or 77 but got %q", s)
+	}
+	var values [2]uint64
+	for i := range values {
+		v, ok := cardValues[s[i:i+1]]
+		if !ok {
+			return HandClass{}, fmt.Errorf("Invalid card value %q in hand class %q", s[i:i+1], s)
+		}
+		values[i] = uint64(v)
+	}
+	c := HandClass{High: values[0], Low: values[1]}
+	if c.High < c.Low {
+		c.High, c.Low = c.Low, c.High
+	}
+	switch {
+	case c.High == c.Low && len(s) == 3:
+		return HandClass{}, fmt.Errorf("Pairs cannot be suited or offsuit but got %q", s)
+	case c.High != c.Low && (len(s) == 2 || (s[2] != 'S' && s[2] != 'O')):
+		return HandClass{}, fmt.Errorf("Expected %q to end in s for suited or o for offsuit", s)
+	}
+	c.Suited = len(s) == 3 && s[2] == 'S'
+	return c, nil
+}
+
+// return every two hole cards in the class
+func (c HandClass) Combos() []CardSet {
+	combos := make([]CardSet, 0, 12)
+	for s1 := uint(0); s1 < 4; s1++ {
+		for s2 := uint(0); s2 < 4; s2++ {
+			pair := c.High == c.Low && s1 < s2
+			suited := c.High != c.Low && c.Suited && s1 == s2
+			offsuit := c.High != c.Low && !c.Suited && s1 != s2
+			if pair || suited || offsuit {
+				combos = append(combos, valueCard(uint(c.High), s1)|valueCard(uint(c.Low), s2))
+			}
+		}
+	}
+	return combos
+}
+
+// every way to rearrange the four suits
+var suitPermutations = func() [][4]uint {
+	perms := make([][4]uint, 0, 24)
+	for a := uint(0); a < 4; a++ {
+		for b := uint(0); b < 4; b++ {
+			for c := uint(0); c < 4; c++ {
+				if a != b && b != c && a != c {
+					perms = append(perms, [4]uint{a, b, c, 6 - a - b - c})
+				}
+			}
+		}
+	}
+	return perms
+}()
+
+// return the set with every card of suit s turned into a card of suit suits[s] (jokers stay jokers)
+func PermuteSuits(cardset CardSet, suits [4]uint) CardSet {
+	permuted := cardset & Jokers
+	for s := uint(0); s < 4; s++ {
+		permuted |= ((cardset >> s) & Clubs) << suits[s]
+	}
+	return permuted
+}
+
+// return the sets (i.e. hole cards and a board) with their suits swapped around the same way so
+// that the sets of every equivalent situation come out the same, and the suits they were swapped
+// with (see PermuteSuits)
+func CanonicalSuits(sets ...CardSet) ([]CardSet, [4]uint) {
+	best := make([]CardSet, len(sets))
+	permuted := make([]CardSet, len(sets))
+	var bestSuits [4]uint
+	for n, suits := range suitPermutations {
+		less := n == 0
+		for i, cardset := range sets {
+			permuted[i] = PermuteSuits(cardset, suits)
+			if !less && permuted[i] != best[i] {
+				if permuted[i] > best[i] {
+					break
+				}
+				less = true
+			}
+		}
+		if less {
+			copy(best, permuted)
+			bestSuits = suits
+		}
+	}
+	return best, bestSuits
+}
@@ -0,0 +1,134 @@
+package poker
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHandClassesPartitionEveryCombo(t *testing.T) {
+	class := make(map[CardSet]HandClass, 1326)
+	var pairs, suited, offsuit int
+	for i := 0; i < HAND_CLASSES; i++ {
+		c := HandClassAt(i)
+		if c.Index() != i {
+			t.Fatalf("Expected %s to be class %d but it is %d", c, i, c.Index())
+		}
+		if parsed, err := ParseHandClass(c.String()); err != nil || parsed != c {
+			t.Fatalf("Expected %s to parse back into itself but got %s (%v)", c, parsed, err)
+		}
+		combos := c.Combos()
+		switch {
+		case c.High == c.Low && len(combos) == 6:
+			pairs++
+		case c.High != c.Low && c.Suited && len(combos) == 4:
+			suited++
+		case c.High != c.Low && !c.Suited && len(combos) == 12:
+			offsuit++
+		default:
+			t.Fatalf("Expected the right number of combos of %s but got %d", c, len(combos))
+		}
+		for _, combo := range combos {
+			if other, ok := class[combo]; ok {
+				t.Fatalf("Expected %s to be in one class but it is in %s and %s", CardSetToString(combo), other, c)
+			}
+			class[combo] = c
+		}
+	}
+	if pairs != 13 || suited != 78 || offsuit != 78 {
+		t.Fatalf("Expected 13 pairs, 78 suited and 78 offsuit classes but got %d, %d and %d", pairs, suited, offsuit)
+	}
+
+	// every two cards are in exactly the class they map to
+	n := 0
+	for combos := NewCombinations(AllCards, 2); combos.Next(); n++ {
+		hole := combos.Set()
+		c, err := ClassOf(hole)
+		if err != nil || class[hole] != c {
+			t.Fatalf("Expected %s to be in %s but got %s (%v)", CardSetToString(hole), class[hole], c, err)
+		}
+	}
+	if n != 1326 || len(class) != n {
+		t.Fatalf("Expected the classes to hold all %d combos but they hold %d", n, len(class))
+	}
+}
+
+func TestHandClasses(t *testing.T) {
+	var tests = []struct {
+		hole  CardSet
+		class string
+		index int
+	}{
+		{AceOfHearts | AceOfSpades, "AA", 0},
+		{AceOfHearts | KingOfHearts, "AKs", 1},
+		{AceOfHearts | KingOfClubs, "AKo", 13},
+		{SevenOfDiamonds | SixOfDiamonds, "76s", 7*13 + 8},
+		{TwoOfClubs | ThreeOfSpades, "32o", 12*13 + 11},
+		{TwoOfClubs | TwoOfDiamonds, "22", 168},
+	}
+	for _, test := range tests {
+		c, err := ClassOf(test.hole)
+		if err != nil || c.String() != test.class || c.Index() != test.index {
+			t.Errorf("Expected %s to be %s (class %d) but got %s (class %d) (%v)", CardSetToString(test.hole), test.class, test.index, c, c.Index(), err)
+		}
+	}
+	if c, err := ParseHandClass(" kas "); err != nil || c.String() != "AKs" {
+		t.Errorf("Expected kas to parse as AKs but got %s (%v)", c, err)
+	}
+	for _, bad := range []string{"", "A", "AK", "AKx", "AAs", "XKs", "AKso", "10Ks"} {
+		if _, err := ParseHandClass(bad); err == nil {
+			t.Errorf("Expected an error parsing hand class %q", bad)
+		}
+	}
+	for _, bad := range []CardSet{AceOfHearts, AceOfHearts | KingOfHearts | QueenOfHearts, AceOfHearts | RedJoker} {
+		if _, err := ClassOf(bad); err == nil {
+			t.Errorf("Expected an error finding the class of %s", CardSetToString(bad))
+		}
+	}
+}
+
+func TestCanonicalSuits(t *testing.T) {
+	// there are 1755 flops that are different up to swapping suits
+	flops := make(map[CardSet]bool)
+	for combos := NewCombinations(AllCards, 3); combos.Next(); {
+		canonical, _ := CanonicalSuits(combos.Set())
+		flops[canonical[0]] = true
+	}
+	if len(flops) != 1755 {
+		t.Fatalf("Expected 1755 different flops but got %d", len(flops))
+	}
+
+	// and hole cards are the same up to swapping suits exactly when they are in the same class
+	holes := make(map[CardSet]HandClass)
+	for combos := NewCombinations(AllCards, 2); combos.Next(); {
+		canonical, _ := CanonicalSuits(combos.Set())
+		c, _ := ClassOf(combos.Set())
+		if other, ok := holes[canonical[0]]; ok && other != c {
+			t.Fatalf("Expected %s to be in %s but it is in %s", CardSetToString(combos.Set()), other, c)
+		}
+		holes[canonical[0]] = c
+	}
+	if len(holes) != HAND_CLASSES {
+		t.Fatalf("Expected %d different hole cards but got %d", HAND_CLASSES, len(holes))
+	}
+
+	// swapping the suits of the hole cards and the board together is the same situation
+	rng := rand.New(rand.NewSource(50))
+	for i := 0; i < 1000; i++ {
+		cards := randomHand(rng, 7)
+		hole := nthCard(cards, 0) | nthCard(cards, 1)
+		board := cards & ^hole
+		canonical, suits := CanonicalSuits(hole, board)
+		if canonical[0] != PermuteSuits(hole, suits) || canonical[1] != PermuteSuits(board, suits) {
+			t.Fatalf("Expected the canonical sets of %s on %s to be swapped by %v", CardSetToString(hole), CardSetToString(board), suits)
+		}
+		perm := suitPermutations[rng.Intn(len(suitPermutations))]
+		if again, _ := CanonicalSuits(PermuteSuits(hole, perm), PermuteSuits(board, perm)); again[0] != canonical[0] || again[1] != canonical[1] {
+			t.Fatalf("Expected %s on %s with the suits swapped by %v to be the same", CardSetToString(hole), CardSetToString(board), perm)
+		}
+	}
+	flush, _ := CanonicalSuits(AceOfHearts|KingOfHearts, QueenOfHearts|SevenOfHearts|TwoOfClubs)
+	noFlush, _ := CanonicalSuits(AceOfHearts|KingOfHearts, QueenOfSpades|SevenOfSpades|TwoOfClubs)
+	if flush[0] == noFlush[0] && flush[1] == noFlush[1] {
+		t.Fatalf("Expected a flush draw and a backdoor flush draw to be different")
+	}
+}
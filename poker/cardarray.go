@@ -0,0 +1,102 @@
+package poker
+
+import (
+	"math/bits"
+)
+
+// The game keeps track of which cards are in the deck and the muck through CardSetLike, and hands the
+// hole cards and the board to the evaluators as one, so the bit-vector (CardSet) is just one way of
+// storing them. CardArray stores one flag per card instead, from the two of clubs up to the ace of
+// spades and then the two jokers, and the whole test suite can be run with it (go test ./poker
+// -cardarray) to check that dealing and scoring do not depend on the bit layout.
+// The evaluators are built on bit tricks, so they take the bit-vector underneath with Bits, and
+// EvaluateFast (the equity calculator's hot path) takes a CardSet directly.
+
+// What the game needs from a set of cards
+type CardSetLike interface {
+	Count() int                    // How many cards are in the set
+	Nth(n int) Card                // The nth lowest card (counting from zero) from the two of clubs up
+	Has(card Card) bool            // Whether the card is in the set
+	With(card Card) CardSetLike    // The set with the card in it
+	Without(card Card) CardSetLike // The set without the card
+	Bits() CardSet                 // The set as a bit-vector (i.e. to evaluate it)
+}
+
+// Builds the sets the game deals from (the bit-vector unless the tests ask otherwise)
+var newCardSetLike = func(cards CardSet) CardSetLike {
+	return cards
+}
+
+// One flag per card, by value (twos first) and then suit (clubs first), followed by the jokers
+type CardArray [54]bool
+
+// return the position of a single card in the array
+func cardIndex(card Card) int {
+	return bits.TrailingZeros64(uint64(CardSet(card) & ^_Ace1s)) - 4
+}
+
+// return the card at a position of the array
+func indexCard(i int) Card {
+	card := TwoOfClubs << uint(i)
+	if card&_Ace2s > 0 {
+		card |= card >> 52
+	}
+	return Card(card)
+}
+
+func NewCardArray(cards CardSet) CardArray {
+	var a CardArray
+	for _, card := range cards.Cards() {
+		a[cardIndex(Card(card))] = true
+	}
+	return a
+}
+
+func (a CardArray) Count() int {
+	n := 0
+	for _, in := range a {
+		if in {
+			n++
+		}
+	}
+	return n
+}
+
+// return the nth lowest card or zero if there are not enough cards
+func (a CardArray) Nth(n int) Card {
+	for i, in := range a {
+		if !in {
+			continue
+		}
+		if n == 0 {
+			return indexCard(i)
+		}
+		n--
+	}
+	return NoCards
+}
+
+func (a CardArray) Has(card Card) bool {
+	i := cardIndex(card)
+	return i >= 0 && i < len(a) && a[i]
+}
+
+func (a CardArray) With(card Card) CardSetLike {
+	a[cardIndex(card)] = true
+	return a
+}
+
+func (a CardArray) Without(card Card) CardSetLike {
+	a[cardIndex(card)] = false
+	return a
+}
+
+func (a CardArray) Bits() CardSet {
+	var cards CardSet = NoCards
+	for i, in := range a {
+		if in {
+			cards |= CardSet(indexCard(i))
+		}
+	}
+	return cards
+}
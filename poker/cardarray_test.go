@@ -0,0 +1,102 @@
+package poker
+
+import (
+	"flag"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+// Run every test with the game dealing from CardArrays with go test ./poker -cardarray
+var cardArray = flag.Bool("cardarray", false, "deal from CardArrays instead of bit-vectors in every test")
+
+func dealFromCardArrays() {
+	newCardSetLike = func(cards CardSet) CardSetLike { return NewCardArray(cards) }
+}
+
+// The cards as the game hands them to the evaluators (a CardArray with -cardarray)
+func like(cards CardSet) CardSetLike {
+	return newCardSetLike(cards)
+}
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if *cardArray {
+		dealFromCardArrays()
+	}
+	os.Exit(m.Run())
+}
+
+func TestCardArrayMatchesCardSet(t *testing.T) {
+	rng := rand.New(rand.NewSource(47))
+	for i := 0; i < 1000; i++ {
+		var cards CardSet = NoCards
+		for _, n := range rng.Perm(52)[:rng.Intn(53)] {
+			cards |= nthCard(AllCards, n)
+		}
+		var set, array CardSetLike = cards, NewCardArray(cards)
+		if array.Bits() != cards || array.Count() != set.Count() {
+			t.Fatalf("Expected an array of %s but got %s", CardSetToString(cards), CardSetToString(array.Bits()))
+		}
+		for n := 0; n <= set.Count(); n++ {
+			if array.Nth(n) != set.Nth(n) {
+				t.Fatalf("Expected card %d of %s to be %s but got %s", n, CardSetToString(cards), set.Nth(n), array.Nth(n))
+			}
+		}
+		card := Card(nthCard(AllCards, rng.Intn(52)))
+		if array.Has(card) != set.Has(card) || array.With(card).Bits() != set.With(card).Bits() || array.Without(card).Bits() != set.Without(card).Bits() {
+			t.Fatalf("Expected the array and the bit-vector of %s to agree about %s", CardSetToString(cards), card)
+		}
+	}
+}
+
+func TestGameDealsFromCardArrays(t *testing.T) {
+	defer func(old func(CardSet) CardSetLike) { newCardSetLike = old }(newCardSetLike)
+	dealFromCardArrays()
+
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100, Mode: GMODE_SHORT_DECK})
+	defer g.Teardown()
+	total := totalChips(g)
+	mustNewRound(t, g)
+	if _, ok := g.deck.(CardArray); !ok {
+		t.Fatalf("Expected the game to deal from a CardArray but got %T", g.deck)
+	}
+	var dealt CardSet = NoCards
+	for _, p := range g.Players() {
+		for _, c := range p.Cards {
+			dealt |= CardSet(c.(Card))
+		}
+	}
+	if left := g.deck.Count(); left != 36-6 || dealt.Count() != 6 || dealt&(g.deck.Bits()|Twos|Threes|Fours|Fives) != 0 {
+		t.Fatalf("Expected six short-deck cards to be dealt apart from the %d left but got %s", left, CardSetToString(dealt))
+	}
+	callAround(t, g, creator, "p1", "p2")
+	for incremented, _ := g.Increment(); incremented; incremented, _ = g.Increment() {
+		for turnName(g) != "" {
+			mustMove(t, g, MTYPE_CHECK, 0, turnName(g))
+		}
+	}
+	if _, err := g.Resolve(); err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+	if totalChips(g) != total {
+		t.Fatalf("Expected %d chips on the table after showdown but there are %d", total, totalChips(g))
+	}
+}
+
+func TestEvaluatorsAgreeOnCardArrays(t *testing.T) {
+	rng := rand.New(rand.NewSource(47))
+	for i := 0; i < 1000; i++ {
+		var hand CardSet = NoCards
+		for _, n := range rng.Perm(54)[:7] {
+			hand |= nthCard(AllCards|Jokers, n)
+		}
+		array := NewCardArray(hand)
+		natural := hand & ^Jokers
+		if Evaluate(NewCardArray(natural)) != Evaluate(natural) || EvaluateShortDeck(NewCardArray(natural), true) != EvaluateShortDeck(natural, true) ||
+			EvaluateLowA5(NewCardArray(natural)) != EvaluateLowA5(natural) || EvaluateLow27(NewCardArray(natural)) != EvaluateLow27(natural) ||
+			EvaluateLowEight(NewCardArray(natural)) != EvaluateLowEight(natural) || EvaluateWild(array, NewCardArray(Twos)) != EvaluateWild(hand, Twos) {
+			t.Fatalf("Expected the evaluators to value %s the same as a CardArray", CardSetToString(hand))
+		}
+	}
+}
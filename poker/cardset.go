@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/bits"
 	"strings"
+	"unicode"
 )
 
 // 13 * 4 = 52 unique cards in poker
@@ -147,6 +148,14 @@ const (
 const AllCards = Clubs | Diamonds | Hearts | Spades
 const NoCards = 0
 
+// two of the spare bits above the aces are jokers (they are only dealt at tables playing with them)
+const (
+	RedJoker CardSet = _Ace2ofSpades << (iota + 1)
+	BlackJoker
+)
+
+const Jokers = RedJoker | BlackJoker
+
 // to visualize use
 // fmt.Printf("% 064b", n) for a CardSet n
 // I'll probably use this in tests
@@ -389,6 +398,134 @@ func extractHighCard(cardset CardSet) CardSet {
 	}
 }
 
+// return the number of cards in the set (aces are only counted once)
+func (cardset CardSet) Count() int {
+	return bits.OnesCount64(uint64(cardset & ^_Ace1s))
+}
+
+// return the cards in the set one by one from the two of clubs up to the ace of spades
+// (aces have both of their bits)
+func (cardset CardSet) Cards() []CardSet {
+	cards := make([]CardSet, 0, cardset.Count())
+	for rest := cardset & ^_Ace1s; rest > 0; rest &= rest - 1 {
+		card := rest & -rest
+		if card&_Ace2s > 0 {
+			card |= card >> 52
+		}
+		cards = append(cards, card)
+	}
+	return cards
+}
+
+// return whether every one of the cards is in the set
+func (cardset CardSet) Contains(cards CardSet) bool {
+	return cardset&cards == cards
+}
+
+// return the set without the cards
+func (cardset CardSet) Remove(cards CardSet) CardSet {
+	return cardset & ^cards
+}
+
+// return the nth lowest card in the set (counting from zero) like nthCard
+func (cardset CardSet) Nth(n int) Card {
+	return Card(nthCard(cardset, n))
+}
+
+// return whether the card is in the set
+func (cardset CardSet) Has(card Card) bool {
+	return cardset.Contains(CardSet(card))
+}
+
+// return the set with the card in it
+func (cardset CardSet) With(card Card) CardSetLike {
+	return cardset | CardSet(card)
+}
+
+// return the set without the card
+func (cardset CardSet) Without(card Card) CardSetLike {
+	return cardset.Remove(CardSet(card))
+}
+
+// return the set itself (it already is a bit-vector)
+func (cardset CardSet) Bits() CardSet {
+	return cardset
+}
+
+// return the nth lowest card in the set (counting from zero) or zero if there are not enough
+// cards, ordered from the two of clubs up to the ace of spades
+func nthCard(cardset CardSet, n int) CardSet {
+	cardset &= ^_Ace1s
+	for ; n > 0 && cardset > 0; n-- {
+		cardset &= cardset - 1
+	}
+	// isolate the lowest bit and give big aces back their small ace
+	card := cardset & -cardset
+	if card&_Ace2s > 0 {
+		card |= card >> 52
+	}
+	return card
+}
+
+// Combinations go through every subset of k cards of a set (i.e. every turn and river left in the
+// deck, jokers included) without allocating anything, lowest cards first:
+//
+//	for c := NewCombinations(deck, 2); c.Next(); {
+//		runout := c.Set()
+//	}
+type Combinations struct {
+	cards   [54]CardSet // every card in the set, with room for the 52 cards and the two jokers
+	index   [54]int     // which of the cards are in the current subset (in order)
+	n       int
+	k       int
+	started bool
+}
+
+func NewCombinations(cardset CardSet, k int) Combinations {
+	c := Combinations{k: k}
+	for rest := cardset & (AllCards | Jokers) & ^_Ace1s; rest > 0; rest &= rest - 1 {
+		c.cards[c.n] = nthCard(rest, 0)
+		c.n++
+	}
+	return c
+}
+
+// Move on to the next subset and return whether there is one
+func (c *Combinations) Next() bool {
+	if c.k < 0 || c.k > c.n {
+		return false
+	}
+	if !c.started {
+		c.started = true
+		for i := 0; i < c.k; i++ {
+			c.index[i] = i
+		}
+		return true
+	}
+	// move up the last card that can go up and put the ones after it right behind it
+	i := c.k - 1
+	for i >= 0 && c.index[i] == c.n-c.k+i {
+		i--
+	}
+	if i < 0 {
+		return false
+	}
+	c.index[i]++
+	for j := i + 1; j < c.k; j++ {
+		c.index[j] = c.index[j-1] + 1
+	}
+	return true
+}
+
+// return the current subset
+func (c *Combinations) Set() CardSet {
+	var set CardSet = NoCards
+	for i := 0; i < c.k; i++ {
+		set |= c.cards[c.index[i]]
+	}
+	return set
+}
+
 // no need to test this, look how simple it is
 // I've tested it manually
 // use it to debug
@@ -449,6 +586,103 @@ func CardSetToString(cardset CardSet) string {
 			card <<= 1
 		}
 	}
+	if cardset&RedJoker > 0 {
+		b.WriteString("JR ")
+	}
+	if cardset&BlackJoker > 0 {
+		b.WriteString("JB ")
+	}
 
 	return strings.TrimRight(b.String(), " ")
 }
+
+// card values by how they are written (tens can be T or 10)
+var cardValues = map[string]uint{
+	"2": 2, "3": 3, "4": 4, "5": 5, "6": 6, "7": 7, "8": 8, "9": 9, "10": 10,
+	"T": 10, "J": 11, "Q": 12, "K": 13, "A": 14,
+}
+
+// suits by letter (in any case) or symbol (filled or not)
+var cardSuits = map[rune]uint{
+	'C': 0, '♣': 0, '♧': 0,
+	'D': 1, '♦': 1, '♢': 1,
+	'H': 2, '♥': 2, '♡': 2,
+	'S': 3, '♠': 3, '♤': 3,
+}
+
+// read the card at the start of the runes and return it with how many runes it took
+func readCard(runes []rune) (CardSet, int, error) {
+	if len(runes) >= 2 {
+		switch strings.ToUpper(string(runes[:2])) {
+		case "JR":
+			return RedJoker, 2, nil
+		case "JB":
+			return BlackJoker, 2, nil
+		}
+	}
+	n := 1
+	if len(runes) >= 2 && runes[0] == '1' && runes[1] == '0' {
+		n = 2
+	}
+	value, ok := cardValues[strings.ToUpper(string(runes[:n]))]
+	if !ok {
+		return 0, 0, fmt.Errorf("Invalid card value %q", string(runes[:n]))
+	}
+	if len(runes) == n {
+		return 0, 0, fmt.Errorf("Missing the suit of card %q", string(runes))
+	}
+	suit, ok := cardSuits[unicode.ToUpper(runes[n])]
+	if !ok {
+		return 0, 0, fmt.Errorf("Invalid suit %q of card %q", string(runes[n]), string(runes[:n+1]))
+	}
+	return valueCard(value, suit), n + 1, nil
+}
+
+// return the card of a value (2 through 14) and suit (0 through 3, from clubs to spades)
+func valueCard(value uint, suit uint) CardSet {
+	card := TwoOfClubs << (4*(value-2) + suit)
+	if value == 14 {
+		card |= card >> 52
+	}
+	return card
+}
+
+// return the card written as (for example) "Ah", "AH", "10c", "Tc" or "A♥" (or "JR" and "JB" for the
+// red and black jokers)
+func ParseCard(s string) (CardSet, error) {
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) == 0 {
+		return 0, fmt.Errorf("Expected a card but got %q", s)
+	}
+	card, n, err := readCard(runes)
+	if err != nil {
+		return 0, err
+	}
+	if n != len(runes) {
+		return 0, fmt.Errorf("Expected one card but got %q", s)
+	}
+	return card, nil
+}
+
+// return the set of cards written like ParseCard, either next to each other (i.e. "AhKd") or
+// separated by spaces or commas, so that it reads what CardSetToString writes
+func ParseCardSet(s string) (CardSet, error) {
+	var cardset CardSet = NoCards
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) || runes[i] == ',' {
+			i++
+			continue
+		}
+		card, n, err := readCard(runes[i:])
+		if err != nil {
+			return 0, fmt.Errorf("Could not parse %q: %v", s, err)
+		}
+		if cardset&card != 0 {
+			return 0, fmt.Errorf("Could not parse %q: %s is there more than once", s, CardSetToString(card))
+		}
+		cardset |= card
+		i += n
+	}
+	return cardset, nil
+}
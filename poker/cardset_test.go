@@ -2,6 +2,7 @@ package poker
 
 import (
 	"fmt"
+	"math/rand"
 	"strings"
 	"testing"
 )
@@ -630,3 +631,179 @@ func TestCardSetToString(t *testing.T) {
 		}
 	}
 }
+
+func TestShortDeck(t *testing.T) {
+	if count := ShortDeck.Count(); count != 36 {
+		t.Errorf("Expected 36 cards in the short deck but got %d", count)
+	}
+	if ShortDeck&(Twos|Threes|Fours|Fives) != 0 {
+		t.Errorf("Expected no twos through fives in the short deck but got %s", CardSetToString(ShortDeck&(Twos|Threes|Fours|Fives)))
+	}
+}
+
+func TestShortDeckCategories(t *testing.T) {
+	type shortDeckTest struct {
+		cards              CardSet
+		tripsBeatStraights bool
+		expected           uint64
+	}
+	var tests = []shortDeckTest{
+		// the ace plays low in A 6 7 8 9
+		{AceOfSpades | SixOfClubs | SevenOfDiamonds | EightOfHearts | NineOfHearts | KingOfClubs, false, HAND_STRAIGHT},
+		{AceOfHearts | SixOfHearts | SevenOfHearts | EightOfHearts | NineOfHearts | KingOfClubs, false, HAND_STRAIGHT_FLUSH},
+		// flushes beat full houses
+		{AceOfHearts | JackOfHearts | NineOfHearts | SevenOfHearts | SixOfHearts | SixOfClubs | SixOfSpades | JackOfDiamonds, false, HAND_FLUSH},
+		// and three of a kind can beat straights
+		{TenOfClubs | TenOfDiamonds | TenOfHearts | NineOfHearts | EightOfSpades | SevenOfClubs | SixOfClubs, false, HAND_STRAIGHT},
+		{TenOfClubs | TenOfDiamonds | TenOfHearts | NineOfHearts | EightOfSpades | SevenOfClubs | SixOfClubs, true, HAND_THREE_OF_A_KIND},
+		// everything else is as usual
+		{Sevens | KingOfClubs | KingOfDiamonds, false, HAND_FOUR_OF_A_KIND},
+		{KingOfClubs | KingOfDiamonds | SevenOfHearts | SevenOfSpades | AceOfHearts, true, HAND_TWO_PAIR},
+		{AceOfClubs | JackOfDiamonds | EightOfHearts | SevenOfSpades | KingOfHearts | SixOfClubs, false, HAND_HIGH_CARD},
+	}
+	for _, test := range tests {
+		if category := HandCategory(EvaluateShortDeck(like(test.cards), test.tripsBeatStraights)); category != test.expected {
+			t.Errorf("%s (trips beat straights = %v) evaluated to category %d but expected %d",
+				CardSetToString(test.cards), test.tripsBeatStraights, category, test.expected)
+		}
+	}
+}
+
+func TestShortDeckOrdering(t *testing.T) {
+	type shortDeckTest struct {
+		better             CardSet
+		worse              CardSet
+		tripsBeatStraights bool
+	}
+	var tests = []shortDeckTest{
+		// A 6 7 8 9 is the lowest straight
+		{SixOfClubs | SevenOfDiamonds | EightOfHearts | NineOfHearts | TenOfSpades, AceOfSpades | SixOfClubs | SevenOfDiamonds | EightOfHearts | NineOfHearts, false},
+		{AceOfSpades | SixOfClubs | SevenOfDiamonds | EightOfHearts | NineOfHearts, AceOfClubs | AceOfDiamonds | AceOfHearts | KingOfClubs | QueenOfDiamonds, false},
+		// flushes beat full houses
+		{SixOfHearts | EightOfHearts | NineOfHearts | TenOfHearts | QueenOfHearts, Aces & ^AceOfSpades | KingOfClubs | KingOfDiamonds, false},
+		// three of a kind can beat straights
+		{SixOfClubs | SixOfDiamonds | SixOfHearts | SevenOfClubs | EightOfDiamonds, TenOfClubs | JackOfDiamonds | QueenOfHearts | KingOfSpades | AceOfClubs, true},
+		{TenOfClubs | JackOfDiamonds | QueenOfHearts | KingOfSpades | AceOfClubs, SixOfClubs | SixOfDiamonds | SixOfHearts | SevenOfClubs | EightOfDiamonds, false},
+	}
+	for _, test := range tests {
+		better := EvaluateShortDeck(like(test.better), test.tripsBeatStraights)
+		worse := EvaluateShortDeck(like(test.worse), test.tripsBeatStraights)
+		if better <= worse {
+			t.Errorf("Expected %s to beat %s (trips beat straights = %v) but got %d and %d",
+				CardSetToString(test.better), CardSetToString(test.worse), test.tripsBeatStraights, better, worse)
+		}
+	}
+}
+
+func TestParseCard(t *testing.T) {
+	var tests = []struct {
+		text string
+		card CardSet
+	}{
+		{"Ah", AceOfHearts},
+		{"AH", AceOfHearts},
+		{"a♥", AceOfHearts},
+		{"10c", TenOfClubs},
+		{"Tc", TenOfClubs},
+		{"2♠", TwoOfSpades},
+		{" kD ", KingOfDiamonds},
+		{"Q♧", QueenOfClubs},
+	}
+	for _, test := range tests {
+		card, err := ParseCard(test.text)
+		if err != nil || card != test.card {
+			t.Errorf("%s parsing %q (%v)", errMsg(card, test.card), test.text, err)
+		}
+	}
+	for _, bad := range []string{"", "A", "1c", "11c", "Ax", "AhKd", "Z♥"} {
+		if _, err := ParseCard(bad); err == nil {
+			t.Errorf("Expected an error parsing card %q", bad)
+		}
+	}
+}
+
+func TestParseCardSet(t *testing.T) {
+	cardset, err := ParseCardSet("Ah, 10c Td2♠ KH")
+	if expected := AceOfHearts | TenOfClubs | TenOfDiamonds | TwoOfSpades | KingOfHearts; err != nil || cardset != expected {
+		t.Errorf("%s (%v)", errMsg(cardset, expected), err)
+	}
+	if _, err := ParseCardSet("Ah Kd ah"); err == nil || !strings.Contains(err.Error(), "AH is there more than once") {
+		t.Errorf("Expected the second ace of hearts to be reported but got `%v`", err)
+	}
+	if _, err := ParseCardSet("Ah Kx"); err == nil || !strings.Contains(err.Error(), `Invalid suit "x" of card "Kx"`) {
+		t.Errorf("Expected the suit of the king to be reported but got `%v`", err)
+	}
+	if cardset, err := ParseCardSet(""); err != nil || cardset != NoCards {
+		t.Errorf("Expected no cards from an empty string but got %s (%v)", CardSetToString(cardset), err)
+	}
+
+	// whatever CardSetToString writes parses back into the same cards
+	rng := rand.New(rand.NewSource(45))
+	for i := 0; i < 1000; i++ {
+		var cardset CardSet = NoCards
+		for _, n := range rng.Perm(52)[:rng.Intn(53)] {
+			cardset |= nthCard(AllCards, n)
+		}
+		if again, err := ParseCardSet(CardSetToString(cardset)); err != nil || again != cardset {
+			t.Fatalf("%s (%v)", errMsg(again, cardset), err)
+		}
+	}
+}
+
+func TestCardSetHelpers(t *testing.T) {
+	hand := AceOfSpades | AceOfHearts | TwoOfClubs
+	if hand.Count() != 3 || AllCards.Count() != 52 || CardSet(NoCards).Count() != 0 {
+		t.Errorf("Expected aces to be counted once but got %d cards in %s", hand.Count(), CardSetToString(hand))
+	}
+	cards := hand.Cards()
+	if len(cards) != 3 || cards[0] != TwoOfClubs || cards[1] != AceOfHearts || cards[2] != AceOfSpades {
+		t.Errorf("Expected the two of clubs and then the aces (with both bits) but got %v", cards)
+	}
+	if !hand.Contains(AceOfHearts|TwoOfClubs) || hand.Contains(AceOfClubs) || !hand.Contains(NoCards) {
+		t.Errorf("Expected %s to contain exactly its own cards", CardSetToString(hand))
+	}
+	if left := hand.Remove(AceOfSpades | KingOfClubs); left != AceOfHearts|TwoOfClubs {
+		t.Errorf("%s", errMsg(left, AceOfHearts|TwoOfClubs))
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	var tests = []struct {
+		cardset CardSet
+		k       int
+		subsets int
+	}{
+		{AllCards, 2, 1326},
+		{AllCards, 5, 2598960},
+		{Aces | Kings, 3, 56},
+		{Aces, 0, 1},
+		{Aces, 4, 1},
+		{Aces, 5, 0},
+		{NoCards, 1, 0},
+		{AllCards | Jokers, 1, 54},
+		{AllCards | Jokers, 2, 1431},
+		{Jokers | Aces, 6, 1},
+	}
+	for _, test := range tests {
+		seen := map[CardSet]bool{}
+		for c := NewCombinations(test.cardset, test.k); c.Next(); {
+			set := c.Set()
+			if set.Count() != test.k || !test.cardset.Contains(set) || seen[set] {
+				t.Fatalf("Expected new subsets of %d cards of %s but got %s", test.k, CardSetToString(test.cardset), CardSetToString(set))
+			}
+			seen[set] = true
+		}
+		if len(seen) != test.subsets {
+			t.Errorf("Expected %d subsets of %d cards of %s but got %d", test.subsets, test.k, CardSetToString(test.cardset), len(seen))
+		}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		for c := NewCombinations(AllCards.Remove(AceOfSpades|KingOfSpades), 2); c.Next(); {
+			c.Set()
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Expected going through the combinations not to allocate but it took %v allocations", allocs)
+	}
+}
@@ -0,0 +1,105 @@
+package poker
+
+import (
+	"fmt"
+)
+
+// Evaluated hands are described for people the way they would say them at the table, i.e.
+// "Full house, Kings full of Sevens" or "Pair of Queens, Ace kicker". The words come from a
+// Locale so that tables can describe hands in other languages (add them to Locales and pick
+// them with GameInitArgs.Language). Only the first kicker is described, since it is the one
+// that usually decides between hands of the same kind.
+
+// The words used to describe hands in one language
+type Locale struct {
+	Values  [15]string        // Card values (1 and 14 are both the ace) as in "Ace high"
+	Plurals [15]string        // Card values as in "Pair of Aces"
+	Hands   map[uint64]string // Format of each category, given the values that make it (see English)
+	Royal   string            // An ace high straight flush
+	Kicker  string            // Format of the first kicker, added to hands that have one
+	Low     string            // Format of a low with no pairs, given its two highest values
+}
+
+var English = Locale{
+	Values: [15]string{"", "Ace", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten",
+		"Jack", "Queen", "King", "Ace"},
+	Plurals: [15]string{"", "Aces", "Twos", "Threes", "Fours", "Fives", "Sixes", "Sevens", "Eights", "Nines",
+		"Tens", "Jacks", "Queens", "Kings", "Aces"},
+	Hands: map[uint64]string{
+		HAND_HIGH_CARD:       "%s high",                   // the highest card
+		HAND_PAIR:            "Pair of %s",                // the pair (plural)
+		HAND_TWO_PAIR:        "Two pair, %s and %s",       // the pairs (plural)
+		HAND_THREE_OF_A_KIND: "Three of a kind, %s",       // the trips (plural)
+		HAND_STRAIGHT:        "Straight, %s high",         // the highest card
+		HAND_FLUSH:           "Flush, %s high",            // the highest card
+		HAND_FULL_HOUSE:      "Full house, %s full of %s", // the trips and the pair (plural)
+		HAND_FOUR_OF_A_KIND:  "Four of a kind, %s",        // the quads (plural)
+		HAND_STRAIGHT_FLUSH:  "Straight flush, %s high",   // the highest card
+		HAND_FIVE_OF_A_KIND:  "Five of a kind, %s",        // the value (plural)
+	},
+	Royal:  "Royal flush",
+	Kicker: ", %s kicker",
+	Low:    "%s-%s low",
+}
+
+// Languages tables can describe hands in, by name
+var Locales = map[string]*Locale{
+	"en": &English,
+}
+
+const DEFAULT_LANGUAGE = "en"
+
+// return the nth value (from zero) packed into the kickers of an evaluated hand
+func handKicker(value uint64, n int) uint64 {
+	return (value >> uint(handCategoryShift-4*(n+1))) & 0xF
+}
+
+// return a description of an evaluated high hand (i.e. "Flush, Ace high")
+func Describe(value uint64) string {
+	return English.Describe(value)
+}
+
+// return a description of an evaluated low hand (i.e. "Seven-Five low")
+func DescribeLow(value uint64) string {
+	return English.DescribeLow(value)
+}
+
+func (l *Locale) Describe(value uint64) string {
+	category := HandCategory(value)
+	k := func(n int) uint64 { return handKicker(value, n) }
+	switch category {
+	case HAND_HIGH_CARD:
+		return fmt.Sprintf(l.Hands[category], l.Values[k(0)]) + l.kicker(k(1))
+	case HAND_PAIR, HAND_THREE_OF_A_KIND, HAND_FOUR_OF_A_KIND:
+		return fmt.Sprintf(l.Hands[category], l.Plurals[k(0)]) + l.kicker(k(1))
+	case HAND_TWO_PAIR:
+		return fmt.Sprintf(l.Hands[category], l.Plurals[k(0)], l.Plurals[k(1)]) + l.kicker(k(2))
+	case HAND_FULL_HOUSE:
+		return fmt.Sprintf(l.Hands[category], l.Plurals[k(0)], l.Plurals[k(1)])
+	case HAND_FIVE_OF_A_KIND:
+		return fmt.Sprintf(l.Hands[category], l.Plurals[k(0)])
+	case HAND_STRAIGHT_FLUSH:
+		if k(0) == 14 {
+			return l.Royal
+		}
+	}
+	// straights and flushes (a wheel is five high)
+	return fmt.Sprintf(l.Hands[category], l.Values[k(0)])
+}
+
+// Lows are described by their two highest cards unless they have a pair (or worse) in them
+func (l *Locale) DescribeLow(value uint64) string {
+	key := lowKeyMax - value
+	if HandCategory(key) != HAND_HIGH_CARD {
+		return l.Describe(key)
+	}
+	return fmt.Sprintf(l.Low, l.Values[handKicker(key, 0)], l.Values[handKicker(key, 1)])
+}
+
+// hands with fewer than five cards may not have a kicker
+func (l *Locale) kicker(value uint64) string {
+	if value == 0 {
+		return ""
+	}
+	return fmt.Sprintf(l.Kicker, l.Values[value])
+}
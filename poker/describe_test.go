@@ -0,0 +1,122 @@
+package poker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	var tests = []struct {
+		hand        string
+		description string
+	}{
+		{"Ah Kd 9c 7s 3h 2d", "Ace high, King kicker"},
+		{"Qh Qd Ac 7s 3h", "Pair of Queens, Ace kicker"},
+		{"Qh Qd 3c 3s Jh 2d", "Two pair, Queens and Threes, Jack kicker"},
+		{"6h 6d 6c As 3h", "Three of a kind, Sixes, Ace kicker"},
+		{"Ah 2d 3c 4s 5h Kd", "Straight, Five high"},
+		{"9h Td Jc Qs Kh", "Straight, King high"},
+		{"Ah 9h 6h 3h 2h Ks", "Flush, Ace high"},
+		{"Kh Kd Kc 7s 7h", "Full house, Kings full of Sevens"},
+		{"Jh Jd Jc Js 7h", "Four of a kind, Jacks, Seven kicker"},
+		{"9s Ts Js Qs Ks", "Straight flush, King high"},
+		{"Ts Js Qs Ks As", "Royal flush"},
+	}
+	for _, test := range tests {
+		if description := Describe(Evaluate(like(mustParseCardSet(t, test.hand)))); description != test.description {
+			t.Errorf("Expected %s to be described as %q but got %q", test.hand, test.description, description)
+		}
+	}
+	if description := Describe(EvaluateWild(like(mustParseCardSet(t, "9h 9d 9c 9s JR")), like(NoCards))); description != "Five of a kind, Nines" {
+		t.Errorf("Expected five nines but got %q", description)
+	}
+	// short-deck ranks its categories differently but the hands are described the same way
+	if description := Describe(EvaluateShortDeck(like(mustParseCardSet(t, "Ah 6d 7c 8s 9h")), false)); description != "Straight, Nine high" {
+		t.Errorf("Expected a short-deck straight to nine but got %q", description)
+	}
+}
+
+func TestDescribeLow(t *testing.T) {
+	var tests = []struct {
+		value       uint64
+		description string
+	}{
+		{EvaluateLowA5(like(mustParseCardSet(t, "Ah 3d 5c 7s 2h Kd Kh"))), "Seven-Five low"},
+		{EvaluateLowA5(like(mustParseCardSet(t, "Ah 2d 3c 4s 5h"))), "Five-Four low"},
+		{EvaluateLowA5(like(mustParseCardSet(t, "Ah Ad 3c 4s 5h 5d 3s"))), "Pair of Aces, Five kicker"},
+		{EvaluateLow27(like(mustParseCardSet(t, "7h 5d 4c 3s 2h"))), "Seven-Five low"},
+		{EvaluateLow27(like(mustParseCardSet(t, "Ah 5d 4c 3s 2h"))), "Ace-Five low"},
+	}
+	for _, test := range tests {
+		if description := DescribeLow(test.value); description != test.description {
+			t.Errorf("Expected %q but got %q", test.description, description)
+		}
+	}
+}
+
+func TestShowdownDescribesHandsInTheTableLanguage(t *testing.T) {
+	if _, _, err := New(pointer(creator), &GameInitArgs{Language: "xx"}); err == nil {
+		t.Fatalf("Expected an error creating a game in an unknown language")
+	}
+
+	// a pirate locale is the English one with different words for the values
+	pirate := English
+	pirate.Plurals[13] = "Kings o' the sea"
+	pirate.Hands = map[uint64]string{}
+	for category, format := range English.Hands {
+		pirate.Hands[category] = format
+	}
+	pirate.Hands[HAND_FULL_HOUSE] = "A boat, %s over %s"
+	Locales["pirate"] = &pirate
+	defer delete(Locales, "pirate")
+
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100, Language: "pirate"})
+	defer g.Teardown()
+	mustNewRound(t, g)
+	callAround(t, g, creator, "p1", "p2")
+	for incremented, _ := g.Increment(); incremented; incremented, _ = g.Increment() {
+		callAround(t, g, "p1", "p2", creator)
+	}
+	g.middle = [5]Card{Card(KingOfHearts), Card(KingOfDiamonds), Card(SevenOfClubs), Card(SevenOfSpades), Card(TwoOfClubs)}
+	for name, hand := range map[string][]CardSet{creator: {KingOfClubs, ThreeOfClubs}, "p1": {FourOfHearts, FiveOfHearts}, "p2": {EightOfHearts, NineOfHearts}} {
+		p, _ := g.getPlayer(pointer(name))
+		p.Hand = []Card{Card(hand[0]), Card(hand[1])}
+	}
+	msg, err := g.Resolve()
+	if err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+	if !strings.Contains(*msg, creator+" wins 300 chips from pot 0 with A boat, Kings o' the sea over Sevens") {
+		t.Fatalf("Expected the full house to be described in the table language but got `%s`", *msg)
+	}
+}
+
+func TestShowdownDescribesHandsTheWayTheyWereScored(t *testing.T) {
+	var tests = []struct {
+		mode        uint64
+		description string
+	}{
+		{DEFAULT_MODE, "Straight, Five high"},
+		{GMODE_TRIPLE_DRAW, "Ace-Five low"},
+		{GMODE_SEVEN_CARD_STUD | GMODE_RAZZ, "Five-Four low"},
+		{GMODE_SEVEN_CARD_STUD | GMODE_HI_LO, "Straight, Five high"},
+	}
+	for _, test := range tests {
+		g := newPlayingTable(t, 2, &GameInitArgs{Stakes: 100, Mode: test.mode})
+		var game *Game
+		switch v := g.(type) {
+		case *Game:
+			game = v
+		case *Stud:
+			game = v.Game
+		case *Draw:
+			game = v.Game
+		}
+		p, _ := game.getPlayer(pointer(creator))
+		p.Hand = []Card{Card(AceOfHearts), Card(TwoOfDiamonds), Card(ThreeOfClubs), Card(FourOfSpades), Card(FiveOfHearts)}
+		if description := game.describe(game.handValue(p, NoCards)); description != test.description {
+			t.Errorf("Expected the wheel to be described as %s in mode %d but got %s", test.description, test.mode, description)
+		}
+		g.Teardown()
+	}
+}
@@ -0,0 +1,96 @@
+package poker
+
+import (
+	"fmt"
+)
+
+// Draw games are played at the same kind of table as hold'em (with blinds) but every player gets
+// five cards face down and there is no board. After every betting round but the last, everyone
+// still in the hand (in order starting left of the button, all in or not) throws away any of their
+// cards and is dealt as many new ones, standing pat by throwing away none. Five-card draw has one
+// draw and the best high hand wins. 2-7 triple draw has three draws, is played fixed-limit and the
+// best 2-7 low wins. When the deck runs out the cards thrown away so far are reshuffled into it.
+
+type Draw struct {
+	*Game
+}
+
+// Throw away some of a player's cards and deal them as many new ones
+func (g *Game) draw(p *Player, discards CardSet) (bool, error) {
+	var hand CardSet = NoCards
+	for _, c := range p.Hand {
+		hand |= CardSet(c)
+	}
+	if discards & ^hand != 0 {
+		return false, fmt.Errorf("%s cannot throw away %s since they do not have it", *p.Name, CardSetToString(discards & ^hand))
+	}
+	if n := discards.Count(); n > g.deck.Count()+g.muck.Count() {
+		return false, fmt.Errorf("Not enough cards left for %s to draw %d", *p.Name, n)
+	}
+	for i, c := range p.Hand {
+		if CardSet(c)&discards == 0 {
+			continue
+		}
+		if g.deck.Count() == 0 {
+			g.deck, g.muck = g.muck, newCardSetLike(NoCards)
+			g.roundLogger.Printf("Reshuffling %d discards into the deck\n", g.deck.Count())
+		}
+		card, err := g.dealCard()
+		if err != nil {
+			return false, err
+		}
+		p.Hand[i] = card
+	}
+	// A player's own discards are only reshuffled for the players after them
+	for _, card := range discards.Cards() {
+		g.muck = g.muck.With(Card(card))
+	}
+	if discards == NoCards {
+		g.roundLogger.Printf("%s stands pat\n", *p.Name)
+	} else {
+		g.roundLogger.Printf("%s draws %d\n", *p.Name, discards.Count())
+	}
+	return true, nil
+}
+
+// Once a betting round is over everyone draws, and once everyone has drawn the next betting
+// round starts (both left of the button)
+func (d *Draw) Increment() (bool, error) {
+	if !d.handInProgress() {
+		return false, fmt.Errorf("Cannot increment when no round is in progress")
+	}
+	if !d.bettingDone() || d.liveCount() <= 1 || d.bettingRound == d.lastRound() {
+		return false, nil
+	}
+	if !d.drawing {
+		d.collectBets()
+		d.drawing = true
+		d.roundLogger.Printf("Draw %d\n", d.bettingRound)
+	} else {
+		d.drawing = false
+		d.bettingRound++
+		d.currentBet = 0
+		d.minRaise = d.betUnit()
+		d.raises = 0
+		d.roundLogger.Printf("Betting round %d\n", d.bettingRound)
+	}
+	for _, id := range d.seats {
+		d.players[id].acted = false
+	}
+	d.turn = d.button
+	d.advanceTurn()
+	return true, nil
+}
+
+func (d *Draw) BombPot(admin *string) (bool, error) {
+	return false, fmt.Errorf("Draw games cannot have bomb pots since there is no board")
+}
+
+// There is no board to run more than once in draw games
+func (d *Draw) RunItOffer() []string {
+	return []string{}
+}
+
+func (d *Draw) AgreeRunIt(name *string, times uint64) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,132 @@
+package poker
+
+import (
+	"testing"
+)
+
+func handOf(g *Game, name string) CardSet {
+	p, _ := g.getPlayer(pointer(name))
+	var hand CardSet = NoCards
+	for _, c := range p.Hand {
+		hand |= CardSet(c)
+	}
+	return hand
+}
+
+func mustIncrement(t *testing.T, g GameLike) {
+	if incremented, err := g.Increment(); !incremented || err != nil {
+		t.Fatalf("Failed to increment (incremented = %v): `%v`", incremented, err)
+	}
+}
+
+func TestFiveCardDrawDrawsOnce(t *testing.T) {
+	d := newPlayingTable(t, 3, &GameInitArgs{Stakes: 100, Mode: GMODE_FIVE_CARD_DRAW}).(*Draw)
+	defer d.Teardown()
+	total := totalChips(d.Game)
+
+	if err := d.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	for _, p := range d.Players() {
+		if len(p.Cards) != 5 {
+			t.Fatalf("Expected %s to be dealt five cards but got %d", p.Name, len(p.Cards))
+		}
+	}
+	if moved, err := d.Move(MTYPE_DRAW, 0, pointer(creator)); moved || err == nil {
+		t.Fatalf("Expected drawing during a betting round to fail (moved = %v)", moved)
+	}
+	callAround(t, d.Game, creator, "p1", "p2")
+
+	// everyone draws starting left of the button and nobody can bet until they are done
+	mustIncrement(t, d)
+	if moved, err := d.Move(MTYPE_CHECK, 0, pointer("p1")); moved || err == nil {
+		t.Fatalf("Expected checking during the draw to fail (moved = %v)", moved)
+	}
+	before := handOf(d.Game, "p1")
+	discards := nthCard(before, 0) | nthCard(before, 3)
+	if moved, err := d.Move(MTYPE_DRAW, uint64(handOf(d.Game, "p2")), pointer("p1")); moved || err == nil {
+		t.Fatalf("Expected throwing away someone else's cards to fail (moved = %v)", moved)
+	}
+	mustMove(t, d.Game, MTYPE_DRAW, uint64(discards), "p1")
+	after := handOf(d.Game, "p1")
+	if after.Count() != 5 || after&discards != 0 || after&before != before & ^discards {
+		t.Fatalf("Expected p1 to swap %s out of %s but has %s", CardSetToString(discards), CardSetToString(before), CardSetToString(after))
+	}
+	pat := handOf(d.Game, "p2")
+	mustMove(t, d.Game, MTYPE_DRAW, 0, "p2")
+	if handOf(d.Game, "p2") != pat {
+		t.Fatalf("Expected p2 to stand pat with %s but has %s", CardSetToString(pat), CardSetToString(handOf(d.Game, "p2")))
+	}
+	if incremented, _ := d.Increment(); incremented {
+		t.Fatalf("Expected the draw to wait for the creator")
+	}
+	mustMove(t, d.Game, MTYPE_DRAW, uint64(handOf(d.Game, creator)), creator)
+
+	// then there is one more betting round and a showdown
+	mustIncrement(t, d)
+	callAround(t, d.Game, "p1", "p2", creator)
+	if incremented, _ := d.Increment(); incremented {
+		t.Fatalf("Expected no more draws after the last betting round")
+	}
+	if _, err := d.Resolve(); err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+	if totalChips(d.Game) != total {
+		t.Fatalf("Expected %d chips on the table after showdown but there are %d", total, totalChips(d.Game))
+	}
+}
+
+func TestTripleDrawReshufflesDiscards(t *testing.T) {
+	if _, _, err := New(pointer(creator), &GameInitArgs{Mode: GMODE_TRIPLE_DRAW | GMODE_HI_LO}); err == nil {
+		t.Fatalf("Expected triple draw not to be playable hi/lo")
+	}
+	d := newPlayingTable(t, 6, &GameInitArgs{Stakes: 100, Mode: GMODE_TRIPLE_DRAW}).(*Draw)
+	defer d.Teardown()
+	total := totalChips(d.Game)
+
+	if err := d.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	// everyone throws away their whole hand on every draw, which is more than the deck has
+	for draw := 1; draw <= 3; draw++ {
+		for turnName(d.Game) != "" {
+			mustMove(t, d.Game, MTYPE_CHECK|MTYPE_CALL, 0, turnName(d.Game))
+		}
+		mustIncrement(t, d)
+		for turnName(d.Game) != "" {
+			name := turnName(d.Game)
+			mustMove(t, d.Game, MTYPE_DRAW, uint64(handOf(d.Game, name)), name)
+		}
+		var dealt CardSet = NoCards
+		for _, p := range d.Players() {
+			for _, c := range p.Cards {
+				dealt |= CardSet(c.(Card))
+			}
+		}
+		if dealt.Count() != 30 || dealt&(d.deck.Bits()|d.muck.Bits()) != 0 {
+			t.Fatalf("Expected thirty different cards in hand apart from the deck and discards after draw %d but got %s", draw, CardSetToString(dealt))
+		}
+		mustIncrement(t, d)
+	}
+	if d.betUnit() != 200 || d.mode&GMODE_FIXED_LIMIT == 0 {
+		t.Fatalf("Expected big bets of 200 in the last fixed-limit betting round but got %d", d.betUnit())
+	}
+	for turnName(d.Game) != "" {
+		mustMove(t, d.Game, MTYPE_CHECK|MTYPE_CALL, 0, turnName(d.Game))
+	}
+
+	// 2-7 low wins
+	p1, _ := d.getPlayer(pointer("p1"))
+	p2, _ := d.getPlayer(pointer("p2"))
+	p1.Hand = []Card{Card(SevenOfClubs), Card(FiveOfHearts), Card(FourOfHearts), Card(ThreeOfHearts), Card(TwoOfHearts)}
+	p2.Hand = []Card{Card(AceOfClubs), Card(AceOfDiamonds), Card(AceOfHearts), Card(AceOfSpades), Card(KingOfClubs)}
+	if d.handValue(p1, NoCards) <= d.handValue(p2, NoCards) {
+		t.Fatalf("Expected 7 5 4 3 2 to beat four aces in 2-7")
+	}
+	if _, err := d.Resolve(); err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+	if totalChips(d.Game) != total {
+		t.Fatalf("Expected %d chips on the table after showdown but there are %d", total, totalChips(d.Game))
+	}
+}
@@ -0,0 +1,120 @@
+package poker
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// Evaluate asks one detector after another for every hand, which is a lot of repeated work when
+// simulations evaluate hundreds of millions of them. EvaluateFast gives exactly the same values from
+// lookup tables instead. Five to seven cards can only make one flush and can not make a flush and a
+// full house (or quads) at once, so a hand with five cards of a suit is worth whatever those values
+// are worth in that suit, looked up by the 13 bits of values. Any other hand is worth only what the
+// counts of each value make, so every way of holding five, six or seven cards with at most four of
+// each value is numbered (a perfect hash, ordered by the number of twos, then threes and so on) and
+// looked up by its number. The tables are filled in from Evaluate the first time they are needed.
+
+const (
+	fastMinCards = 5
+	fastMaxCards = 7
+	fastValues   = 13 // twos through aces
+)
+
+var fastTables struct {
+	once sync.Once
+	// ways[i][n] is how many ways there are to hold n cards of values i and above
+	ways [fastValues + 1][fastMaxCards + 1]uint32
+	// offset[i][n][c] is how far the number moves when c of the n cards left are of value i
+	offset  [fastValues][fastMaxCards + 1][5]uint32
+	counted [fastMaxCards + 1][]uint64 // hands without a flush by how many cards and their number
+	flushes [1 << fastValues]uint64    // flushes by their values
+}
+
+// return the card of value i (twos are zero) and suit s (clubs are zero)
+func fastCard(i int, s int) CardSet {
+	card := TwoOfClubs << uint(4*i+s)
+	if card&_Ace2s > 0 {
+		card |= card >> 52
+	}
+	return card
+}
+
+func buildFastTables() {
+	t := &fastTables
+	t.ways[fastValues][0] = 1
+	for i := fastValues - 1; i >= 0; i-- {
+		for n := 0; n <= fastMaxCards; n++ {
+			for c := 0; c <= 4 && c <= n; c++ {
+				t.offset[i][n][c] = t.ways[i][n]
+				t.ways[i][n] += t.ways[i+1][n-c]
+			}
+		}
+	}
+
+	// one hand for every way of holding n cards, dealing suits in turn so there is never a flush
+	for n := fastMinCards; n <= fastMaxCards; n++ {
+		t.counted[n] = make([]uint64, t.ways[0][n])
+		var fill func(i int, left int, index uint32, hand CardSet, suit int)
+		fill = func(i int, left int, index uint32, hand CardSet, suit int) {
+			if i == fastValues {
+				if left == 0 {
+					t.counted[n][index] = evaluate(hand)
+				}
+				return
+			}
+			for c := 0; c <= 4 && c <= left; c++ {
+				fill(i+1, left-c, index+t.offset[i][left][c], hand, suit+c)
+				hand |= fastCard(i, (suit+c)%4)
+			}
+		}
+		fill(0, n, 0, NoCards, 0)
+	}
+
+	for values := 0; values < 1<<fastValues; values++ {
+		if bits.OnesCount(uint(values)) < fastMinCards {
+			continue
+		}
+		var hand CardSet = NoCards
+		for i := 0; i < fastValues; i++ {
+			if values&(1<<uint(i)) > 0 {
+				hand |= fastCard(i, 0)
+			}
+		}
+		t.flushes[values] = evaluate(hand)
+	}
+}
+
+// Evaluate five to seven cards the same way as Evaluate but from lookup tables (any other number of
+// cards is left to Evaluate)
+func EvaluateFast(cardset CardSet) uint64 {
+	cards := cardset & ^_Ace1s
+	n := bits.OnesCount64(uint64(cards))
+	if n < fastMinCards || n > fastMaxCards {
+		return evaluate(cardset)
+	}
+	t := &fastTables
+	t.once.Do(buildFastTables)
+
+	for s := uint(0); s < 4; s++ {
+		suit := cards & (Clubs << s)
+		if bits.OnesCount64(uint64(suit)) < fastMinCards {
+			continue
+		}
+		values := 0
+		for i := 0; i < fastValues; i++ {
+			if suit&(TwoOfClubs<<(4*uint(i)+s)) > 0 {
+				values |= 1 << uint(i)
+			}
+		}
+		return t.flushes[values]
+	}
+
+	var index uint32 = 0
+	left := n
+	for i := 0; i < fastValues; i++ {
+		c := bits.OnesCount64(uint64(cards>>(4*uint(i)+4)) & 0xF)
+		index += t.offset[i][left][c]
+		left -= c
+	}
+	return t.counted[n][index]
+}
@@ -0,0 +1,140 @@
+package poker
+
+import (
+	"flag"
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// The check over every seven card hand takes minutes so it only runs when asked for with
+// go test ./poker -run TestEvaluateFastEverySevenCards -exhaustive
+var exhaustive = flag.Bool("exhaustive", false, "check EvaluateFast against Evaluate on every seven card hand")
+
+// return n different cards at random
+func randomHand(rng *rand.Rand, n int) CardSet {
+	var hand CardSet = NoCards
+	for _, i := range rng.Perm(52)[:n] {
+		hand |= nthCard(AllCards, i)
+	}
+	return hand
+}
+
+func TestEvaluateFastMatchesEvaluate(t *testing.T) {
+	var tests = []CardSet{
+		AceOfSpades | KingOfSpades | QueenOfSpades | JackOfSpades | TenOfSpades | NineOfSpades | TwoOfClubs,
+		AceOfClubs | TwoOfClubs | ThreeOfDiamonds | FourOfHearts | FiveOfSpades | KingOfClubs,
+		AceOfClubs | AceOfDiamonds | AceOfHearts | AceOfSpades | KingOfClubs | KingOfDiamonds | KingOfHearts,
+		TwoOfHearts | FiveOfHearts | SevenOfHearts | NineOfHearts | JackOfHearts | JackOfClubs | JackOfDiamonds,
+		SevenOfClubs | SevenOfDiamonds | SixOfHearts | SixOfSpades | TwoOfClubs,
+		// fewer or more cards are left to Evaluate
+		AceOfClubs | KingOfClubs,
+		Aces | Kings,
+	}
+	for _, hand := range tests {
+		if fast, slow := EvaluateFast(hand), Evaluate(hand); fast != slow {
+			t.Errorf("Expected %s to be worth %x but got %x", CardSetToString(hand), slow, fast)
+		}
+	}
+	rng := rand.New(rand.NewSource(44))
+	for i := 0; i < 100000; i++ {
+		hand := randomHand(rng, fastMinCards+i%(fastMaxCards-fastMinCards+1))
+		if fast, slow := EvaluateFast(hand), Evaluate(hand); fast != slow {
+			t.Fatalf("Expected %s to be worth %x but got %x", CardSetToString(hand), slow, fast)
+		}
+	}
+}
+
+func TestEvaluateFastEveryFiveCards(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping every five card hand in short mode")
+	}
+	for c := NewCombinations(AllCards, 5); c.Next(); {
+		hand := c.Set()
+		if fast, slow := EvaluateFast(hand), Evaluate(hand); fast != slow {
+			t.Fatalf("Expected %s to be worth %x but got %x", CardSetToString(hand), slow, fast)
+		}
+	}
+}
+
+func TestEvaluateFastEverySevenCards(t *testing.T) {
+	if !*exhaustive {
+		t.Skip("skipping every seven card hand unless -exhaustive is given")
+	}
+	deck := make([]CardSet, 52)
+	for i := range deck {
+		deck[i] = nthCard(AllCards, i)
+	}
+	// split the hands up by their highest card
+	var wg sync.WaitGroup
+	highs := make(chan int, 52)
+	for high := 6; high < 52; high++ {
+		highs <- high
+	}
+	close(highs)
+	var checked int64 = 0
+	var mu sync.Mutex
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for high := range highs {
+				// every six cards below the highest, without holding them all in memory
+				n := int64(0)
+				var rest func(below int, left int, hand CardSet) bool
+				rest = func(below int, left int, hand CardSet) bool {
+					if left == 0 {
+						n++
+						if fast, slow := EvaluateFast(hand), Evaluate(hand); fast != slow {
+							t.Errorf("Expected %s to be worth %x but got %x", CardSetToString(hand), slow, fast)
+							return false
+						}
+						return true
+					}
+					for i := below - 1; i >= left-1; i-- {
+						if !rest(i, left-1, hand|deck[i]) {
+							return false
+						}
+					}
+					return true
+				}
+				rest(high, 6, deck[high])
+				mu.Lock()
+				checked += n
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if checked != 133784560 {
+		t.Fatalf("Expected to check all 133784560 seven card hands but checked %d", checked)
+	}
+}
+
+// the same hands for every benchmark
+func benchmarkHands() []CardSet {
+	rng := rand.New(rand.NewSource(44))
+	hands := make([]CardSet, 1024)
+	for i := range hands {
+		hands[i] = randomHand(rng, 7)
+	}
+	return hands
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	hands := benchmarkHands()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Evaluate(hands[i%len(hands)])
+	}
+}
+
+func BenchmarkEvaluateFast(b *testing.B) {
+	hands := benchmarkHands()
+	EvaluateFast(hands[0])
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EvaluateFast(hands[i%len(hands)])
+	}
+}
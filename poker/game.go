@@ -1,10 +1,14 @@
 package poker
 
+import (
+	"time"
+)
+
 // Every game is broken into an infinite sequence of rounds which is broken up into four betting rounds.
 // In any given round, players are playing or not, and in any game they are admins or not.
 // Playing players can check, fold, call, bet, call any (plans a future action) or sit out the next
 // round (plans a future action). A game can be playing or not (paused) and private or not (public).
-// The only currently supported game mode is regular no-limit Texas hold'em with no rake.
+// Games are no-limit Texas hold'em with no rake unless their mode says otherwise (i.e. pot-limit Omaha).
 
 // Games are joinable by join codes (passwords) if private, and simply by request if public. Inside each
 // game players can become admins or lose their admin status. The creator of a game is the original admin.
@@ -25,6 +29,7 @@ const (
 	BROUND_FLOP
 	BROUND_TURN
 	BROUND_RIVER
+	BROUND_SEVENTH_STREET // Stud has five betting rounds (third through seventh street)
 )
 
 // Move Types
@@ -35,12 +40,19 @@ const (
 	MTYPE_CALL_ANY
 	MTYPE_BET
 	MTYPE_SITOUT_NEXT_ROUND
+	MTYPE_DRAW // Throw away cards (given as a CardSet in place of chips) and draw new ones in draw games
 )
 
 // Player Status
 const (
 	PSTATUS_ADMIN uint64 = 1 << iota
 	PSTATUS_PLAYING
+	PSTATUS_FOLDED
+	PSTATUS_ALL_IN
+	PSTATUS_AWAY    // Disconnected; the seat and stack are kept for a grace period
+	PSTATUS_LEAVING // Left or was kicked mid-round; the seat is freed at the end of the round
+	PSTATUS_SITTING_OUT // Not dealt in; missing blinds that have to be made up on return
+	PSTATUS_WAIT_FOR_BB // Sitting in but waiting for the big blind rather than posting what they owe
 )
 
 // Player Permissions (right now same as status)
@@ -55,9 +67,34 @@ const (
 	GSTATUS_PRIVATE
 )
 
+// Straddles (a voluntary third blind of twice the big blind)
+const (
+	STRADDLE_NONE   uint64 = iota
+	STRADDLE_UTG           // Posted by the player left of the big blind
+	STRADDLE_BUTTON        // Posted by the button (the blinds act first and the button acts last)
+)
+
+// Ante Structures
+const (
+	ANTE_NONE      uint64 = iota
+	ANTE_EVERYONE         // Every player dealt in posts an ante
+	ANTE_BIG_BLIND        // The big blind posts a single ante for the whole table
+)
+
 // Game Mode
 const (
 	GMODE_CONST_STAKES uint64 = 1 << iota
+	GMODE_POT_LIMIT_OMAHA      // Four hole cards, exactly two of which (and three from the board) make a hand (fixed-limit with GMODE_FIXED_LIMIT)
+	GMODE_SHORT_DECK           // No twos through fives (A 6 7 8 9 is a straight and flushes beat full houses)
+	GMODE_TRIPS_BEAT_STRAIGHTS // In short-deck three of a kind beats a straight
+	GMODE_HI_LO                // Pots are split between the best high and the best eight-or-better low
+	GMODE_FIXED_LIMIT          // Bets and raises are one small bet (two after the flop) and capped
+	GMODE_SEVEN_CARD_STUD      // Seven cards each (three face down) with antes, a bring-in and no board
+	GMODE_FIVE_CARD_DRAW       // Five cards each and no board, with one draw before the last betting round
+	GMODE_TRIPLE_DRAW          // 2-7 triple draw: five cards each, three draws and the best 2-7 low wins
+	GMODE_RAZZ                 // Seven-card stud won by the best A-5 low (the highest card brings it in)
+	GMODE_DEUCES_WILD          // Every two is wild (five of a kind beats a straight flush)
+	GMODE_JOKERS_WILD          // Two jokers are added to the deck and are wild
 )
 
 // Defaults for standard games
@@ -67,8 +104,20 @@ const (
 	DEFAULT_MODE uint64                   = GMODE_CONST_STAKES // Standard games have constant stakes.
 	DEFAULT_STATUS uint64                 = 0                  // The default status is the null status (not started)
 	DEFAULT_STAKES_HAND_MULTIPLIER uint64 = 100                // DEFAULT_STAKES * ..._MULTIPLIER = default starting hand
+	DEFAULT_MAX_SITOUT_ORBITS uint64      = 3                  // Players who sit out for three orbits lose their seat
+	DEFAULT_STRADDLE_MULTIPLIER uint64    = 2                  // A straddle is twice the big blind
+	DEFAULT_HANDS_PER_GAME uint64         = 8                  // Mixed games switch games every eight hands
+)
+
+const (
+	DEFAULT_AWAY_GRACE        time.Duration = 3 * time.Minute  // Disconnected players keep their seat for three minutes
+	DEFAULT_SEAT_OFFER_WINDOW time.Duration = 30 * time.Second // Waiting players have thirty seconds to take a free seat
 )
 
+const MAX_RAIL_MESSAGES = 64 // Only the most recent rail messages are kept
+const MAX_LIMIT_BETS = 4     // Fixed-limit betting rounds are capped at a bet and three raises
+const MAX_STUD_PLAYERS = 8   // Nine players would need more than the deck by sixth street
+
 // A GameLike should be able to manipulate CardLikes accordingly. The string method
 // will be desired to communiate with players. Format is "<number><suit>" i.e. "10H" for ten of hearts.
 type CardLike interface {
@@ -83,8 +132,30 @@ type PlayerInfo struct {
 
 	// Game Server-Only
 	Id    uint64
-	Cards [2]CardLike
+	Cards []CardLike
+	Up    []bool // Which cards are face up for everyone to see (i.e. in stud)
 	Mod   bool
+	Away  bool
+
+	SittingOut bool // Not dealt in (by choice or while waiting for the big blind)
+	OwesBlinds bool // Has to post missed blinds (or wait for the big blind) to be dealt in
+}
+
+// When a player leaves (or is kicked, or is away for too long) whatever is left of their
+// stack is recorded so that it can be paid out or restored later.
+type CashOut struct {
+	Name  string
+	Chips uint64
+	Round uint64 // The round during which they left
+}
+
+// Players who try to join a full table are put on a waitlist (AddPlayer returns their name without
+// joining them). When a seat frees up it is offered to the front of the waitlist, and if the offer
+// is not accepted before it expires the player loses their place and the seat passes to the next.
+type WaitlistEntry struct {
+	Name    string
+	Offered bool      // Whether they have been offered a seat
+	Expires time.Time // When the offer passes on (only meaningful if they have been offered a seat)
 }
 
 // In game likes, control plane functions are used by game servers
@@ -93,14 +164,29 @@ type PlayerInfo struct {
 type GameLike interface {
 	// Player Control
 	KickPlayer(*string, *string) (bool, error)        // (kicker name, kicked name) => (kicked, error)
+	LeavePlayer(*string) (bool, error)                // (leaving player name) => (left, error)
 	ModPlayer(*string, *string, uint64) (bool, error) // (modder name, modded name, mod perms) => (modded, error)
 
 	// Player Control Plane
 	AddPlayer(*string, *string) (*string, bool, error) // (prospective player name, join code) => (player name, joined, error)
+	Waitlist() []WaitlistEntry                         // () => (players waiting for a seat in order)
+	AcceptSeat(*string) (bool, error)                  // (waiting player name) => (seated, error)
+	LeaveWaitlist(*string) (bool, error)               // (waiting player name) => (left the waitlist, error)
+	Disconnect(*string) (bool, error)                  // (disconnected player) => (marked away, error)
+	Reconnect(*string) (bool, error)                   // (returning player) => (resumed seat, error)
 	Players() []*PlayerInfo                            // () => (an informative list of players in order of play)
 	Stakes() uint64                                    // () => (value of big blind in chips)
 	Middle() *[5]CardLike                              // () => (array of cards in the middle)
 	Pots() []uint64                                    // () => (a slice of monetary values of pots)
+	CashOuts() []CashOut                               // () => (stacks of players who have left, oldest first)
+
+	// Spectators (the rail) can watch without a seat
+	AddSpectator(*string, *string) (*string, bool, error) // (prospective spectator name, join code) => (spectator name, joined, error)
+	RemoveSpectator(*string) (bool, error)                // (spectator name) => (removed, error)
+	Spectators() []string                                 // () => (names of spectators in order of arrival)
+	SpectatorPlayers() []*PlayerInfo                      // () => (players as seen from the rail)
+	RailMessage(*string, *string) (bool, error)           // (spectator name, message) => (sent, error)
+	RailChat() []string                                   // () => (most recent rail messages, oldest first)
 
 	// Game Status
 	ChangeGameName(*string, *string) (bool, error) // (name changer, desired name) => (changed name, error)
@@ -110,17 +196,25 @@ type GameLike interface {
 	MakePublic(*string) (bool, error)              // (make public requester) => (made public, error)
 	Playing() bool                                 // () => (game is playing)
 	Private() bool                                 // () => (game is private)
+	Mode() uint64                                  // () => (mode of the game being played, which mixed games change between hands)
+	OnModeChange(func([]string, uint64))           // (listener of (everyone at the table, new mode)) => ()
 
 	// Game Flow
-	Move(uint64, uint64, *string) (bool, error)                        // (move, chips: optional, mover) => (moved, error)
+	Move(uint64, uint64, *string) (bool, error)                        // (move, chips or discards: optional, mover) => (moved, error)
+	SitIn(*string, bool) (bool, error)                                 // (player, post owed blinds rather than wait for the big blind) => (sitting in, error)
 	ChangePlayerName(*string, *string, *string) (*string, bool, error) // (namer, player, new name) => (new name, renamed, error)
 	GiveChips(*string, *string, uint64) (bool, error)
 
 	// Game Flow Control Plane
-	Increment() (bool, error)  // () => (incremented, error)
-	Resolve() (*string, error) // () => (winners' informative message, error)
-	NewRound() error           // () => (error)
-	Renew() error              // () => (error)
+	Straddle(*string, uint64) (bool, error)   // (admin, straddle type) => (next hand is straddled, error)
+	BombPot(*string) (bool, error)            // (admin) => (next hand is a bomb pot, error)
+	ChooseGame(*string, uint64) (bool, error) // (player on the button next, game mode) => (chosen for dealer's choice, error)
+	RunItOffer() []string                     // () => (players who have to agree to run the board more than once)
+	AgreeRunIt(*string, uint64) (bool, error) // (player, times to run the board) => (agreed, error)
+	Increment() (bool, error)                 // () => (incremented, error)
+	Resolve() (*string, error)                // () => (winners' informative message, error)
+	NewRound() error                          // () => (error)
+	Renew() error                             // () => (error)
 
 	// System Maintenance
 	// There should exist a function NewGame(...) or InitGame(...) that
@@ -150,4 +244,29 @@ type GameInitArgs struct {
 	// Renew Information (if you keep chips you must keep players)
 	KeepPlayers bool
 	KeepChips   bool
+
+	// How long a disconnected player keeps their seat and stack (zero is the default)
+	AwayGrace time.Duration
+	// How long a waiting player has to take a free seat (zero is the default)
+	SeatOfferWindow time.Duration
+	// Spectators see every hole card of a hand once it is over and this long after it was dealt
+	// (zero means they never do)
+	TVDelay time.Duration
+	// Players who sit out for this many orbits lose their seat (zero is the default)
+	MaxSitOutOrbits uint64
+
+	// Forced bets on every hand (admins can also straddle or bomb a single hand)
+	Straddle      uint64 // Which straddle is posted every hand (i.e. STRADDLE_UTG)
+	AnteStructure uint64 // Who posts antes (i.e. ANTE_BIG_BLIND)
+	Ante          uint64 // Chips per ante (zero is one big blind for a big blind ante and a tenth of one otherwise)
+	BombPotAnte   uint64 // Chips every player antes in a bomb pot (zero is one big blind)
+	BombPotEvery  uint64 // Every this many hands is a bomb pot (zero is never)
+
+	// Mixed games switch between games (i.e. HORSE_GAMES) instead of playing a single mode
+	Games         []uint64 // Modes of the games played in order (none is not mixed)
+	HandsPerGame  uint64   // Hands played of each game before switching (zero is the default)
+	DealersChoice bool     // The player on the button chooses the next game rather than playing them in order
+
+	// Showdown messages describe hands in this language (one of Locales, empty is the default)
+	Language string
 }
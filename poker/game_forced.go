@@ -0,0 +1,122 @@
+package poker
+
+import (
+	"fmt"
+)
+
+// Besides the blinds a table can force bets with straddles (a third blind of twice the big blind,
+// posted either under the gun or on the button) and antes (posted by everyone or by the big blind
+// for everyone). Antes are dead money: they go straight into the pot and do not count towards
+// calling. A bomb pot skips the blinds altogether; every player antes and the hand is dealt straight
+// to the flop. Tables can straddle every hand or bomb every few hands, and admins can call either
+// for the next hand only.
+
+var straddleNames = map[uint64]string{
+	STRADDLE_NONE:   "NONE",
+	STRADDLE_UTG:    "UTG",
+	STRADDLE_BUTTON: "BUTTON",
+}
+
+var anteStructureNames = map[uint64]string{
+	ANTE_NONE:      "NONE",
+	ANTE_EVERYONE:  "EVERYONE",
+	ANTE_BIG_BLIND: "BIG_BLIND",
+}
+
+// Straddle the next hand (STRADDLE_NONE goes back to the table's usual straddle)
+func (g *Game) Straddle(admin *string, straddle uint64) (bool, error) {
+	if _, found := straddleNames[straddle]; !found {
+		return false, fmt.Errorf("Unknown straddle %d", straddle)
+	}
+	return g.onlyExecuteIfIsAdmin(admin, func() (bool, error) {
+		g.nextStraddle = straddle
+		return true, nil
+	})
+}
+
+func (g *Game) BombPot(admin *string) (bool, error) {
+	return g.onlyExecuteIfIsAdmin(admin, func() (bool, error) {
+		g.nextBombPot = true
+		return true, nil
+	})
+}
+
+// Return the straddle for the next round and whether it is a bomb pot, clearing whatever
+// an admin called for it
+func (g *Game) takeForcedBets() (uint64, bool) {
+	straddle := g.straddle
+	if g.nextStraddle != STRADDLE_NONE {
+		straddle = g.nextStraddle
+	}
+	bomb := g.nextBombPot || (g.bombPotEvery > 0 && (g.roundNum+1)%g.bombPotEvery == 0)
+	g.nextStraddle, g.nextBombPot = STRADDLE_NONE, false
+	return straddle, bomb
+}
+
+// Return the seat that posts the straddle or -1 if nobody can (the blinds cannot straddle)
+func (g *Game) straddleSeat(straddle uint64, small int, big int) int {
+	seat := -1
+	switch straddle {
+	case STRADDLE_UTG:
+		seat = g.nextSeat(big, inHand)
+	case STRADDLE_BUTTON:
+		seat = g.button
+	}
+	if seat < 0 || seat == small || seat == big || !canAct(g.seated(seat)) {
+		return -1
+	}
+	return seat
+}
+
+// The straddle is a live blind so the straddler acts last before the flop
+func (g *Game) postStraddle(seat int) {
+	p := g.seated(seat)
+	straddle := DEFAULT_STRADDLE_MULTIPLIER * g.stakes
+	if p.Bet < straddle {
+		g.putIn(p, straddle-p.Bet)
+	}
+	if p.Bet > g.currentBet {
+		g.currentBet = p.Bet
+		g.minRaise = p.Bet
+	}
+	g.roundLogger.Printf("%s straddles %d\n", *p.Name, p.Bet)
+}
+
+func (g *Game) postAntes(big int) {
+	switch g.anteStructure {
+	case ANTE_EVERYONE:
+		for _, id := range g.seats {
+			if p := g.players[id]; inHand(p) {
+				g.postDead(p, g.ante)
+			}
+		}
+	case ANTE_BIG_BLIND:
+		g.postDead(g.seated(big), g.ante)
+	}
+}
+
+// Everyone dealt in antes and the flop is dealt without any betting before it
+func (g *Game) postBombPot() error {
+	for _, id := range g.seats {
+		if p := g.players[id]; inHand(p) {
+			g.postDead(p, g.bombPotAnte)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		card, err := g.dealCard()
+		if err != nil {
+			return err
+		}
+		g.middle[i] = card
+	}
+	g.bettingRound = BROUND_FLOP
+	g.currentBet = 0
+	g.minRaise = g.betUnit()
+	g.raises = 0
+	g.pots = g.buildPots()
+	g.roundLogger.Printf("Round %d: %s has the button, bomb pot of %d each: %s\n", g.roundNum,
+		*g.seated(g.button).Name, g.bombPotAnte, g.middleCards().String())
+	g.turn = g.button
+	g.advanceTurn()
+	return nil
+}
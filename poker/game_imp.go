@@ -5,8 +5,11 @@ import (
 	"os"
 	"log"
 	"io/ioutil"
+	"math/rand"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 	"encoding/json"
 	"github.com/4gatepylon/GoPoker/utils"
 )
@@ -27,12 +30,20 @@ type Player struct {
 	Id   uint64    // Players have unique identifiers for the system
 	Name *string   // and display names for people
 
-	Hand   [2]Card // A player has a two-card hand
+	Hand   []Card  // A player has two hole cards (four in Omaha)
 	Chips  uint64  // and a positive number of chips
 	Bet    uint64  // Number of chips in play that are bet
 	Pot    uint64  // Number of chips in the pot not being bet
 	Status uint64  // and a status (i.e. is this player an admin? is he playing?)
 	GameId uint64  // Each player is in a game or in the zero game id, which is lobby
+
+	AwaySince time.Time // When the player disconnected (only meaningful while they are away)
+	acted     bool      // Whether the player has acted in the current betting round
+
+	owesBig   bool   // Has to post a big blind to be dealt in (missed it or just joined)
+	owesSmall bool   // Has to post a dead small blind to be dealt in (missed it)
+	orbitsOut uint64 // Big blinds missed in a row while sitting out
+	up        []bool // Which cards in the hand are face up (only in stud)
 }
 
 type Pot struct {
@@ -52,9 +63,42 @@ type Game struct {
 	players       map[uint64]*Player   // Up to the MaxPlayers number of players (recommended is six)
 	status        uint64               // Private | Public, Playing | Paused, etc...
 	pots          []Pot
-	bettingRound  uint64
+	cashOuts      []CashOut            // Stacks of players who have left
+	waitlist      []*waitlistEntry     // Players waiting for a seat at a full table, in order
+	offerWindow   time.Duration        // How long a player has to accept a seat before it passes on
+	spectators    []*string            // Watching without a seat (they do not count towards maxPlayers)
+	rail          []string             // Recent rail chat messages, oldest first
+	tvDelay       time.Duration        // How long after the deal spectators see a finished hand's hole cards (zero means never)
+	dealtAt       time.Time            // When the hole cards of the current round were dealt
+	maxSitOut     uint64               // Players who sit out for this many orbits lose their seat
+	straddle      uint64               // The straddle posted every hand (STRADDLE_NONE for none)
+	anteStructure uint64               // Who posts antes
+	ante          uint64               // Chips per ante
+	bombPotAnte   uint64               // Chips every player antes in a bomb pot
+	bombPotEvery  uint64               // Every this many rounds is a bomb pot (zero is never)
+	nextStraddle  uint64               // A straddle called by an admin for the next round only
+	nextBombPot   bool                 // Whether an admin called a bomb pot for the next round
+	runItVotes    map[uint64]uint64    // How many times each player all in agreed to run the board
+	bettingRound  uint64               // Zero when no round is in progress
 	roundNum      uint64
 
+	// Turn order
+	seats         []uint64             // Player ids in order of play
+	button        int                  // Index in seats of the dealer button
+	turn          int                  // Index in seats of the player who must act (-1 if nobody)
+	deck          CardSetLike          // Cards which have not been dealt this round
+	currentBet    uint64               // The largest bet in the current betting round
+	minRaise      uint64               // The smallest legal raise in the current betting round
+	raises        int                  // Bets and raises so far in the current betting round
+	drawing       bool                 // Whether players are drawing rather than betting (only in draw games)
+	muck          CardSetLike          // Cards thrown away in draws this round (reshuffled when the deck runs out)
+	games         []uint64             // The modes a mixed game switches between (none otherwise)
+	modeListener  func([]string, uint64) // Told about every switch of games (see OnModeChange)
+	awayGrace     time.Duration        // How long a disconnected player keeps their seat and stack
+	now           func() time.Time     // Clock used for away players (swappable for tests)
+	rng           *rand.Rand           // Used to deal cards
+	locale        *Locale              // Words used to describe hands at showdown
+
 	mode          uint64 // The game mode (i.e. constant stakes)
 	stakes        uint64 // The Value of big blind (3x little blind)
 	startingChips uint64 // The amount of chips to give to new players when they join (default will be 10x bb)
@@ -74,30 +118,78 @@ const gameInitName = "info.json"
 
 // Human readable encoding (json) for gameInit file
 type gameInitJson struct {
-	Id            string   `json: game-id`
-	Name          string   `json: game-name`
-	JoinCode      string   `json: game-join-code`
-	Status        string   `json: game-status`
-	MaxPlayers    uint64   `json: max-players`
-	Stakes        uint64   `json: big-blind`
-	StartingChips uint64   `json: starting-chips`
-	Mode          string   `json: game-mode`
-	KeepPlayers   bool    `json: keep-players-on-renew`
-	KeepChips     bool    `json: keep-chips-on-renew`
+	Id            string
+	Name          string
+	JoinCode      string
+	Status        string
+	MaxPlayers    uint64
+	Stakes        uint64
+	StartingChips uint64
+	Mode          string
+	KeepPlayers   bool
+	KeepChips     bool
+	AwayGrace     string
+	OfferWindow   string
+	TVDelay       string
+	MaxSitOut     uint64
+	Straddle      string
+	AnteStructure string
+	Ante          uint64
+	BombPotAnte   uint64
+	BombPotEvery  uint64
+	Games         []string `json:",omitempty"`
+	HandsPerGame  uint64   `json:",omitempty"`
+	DealersChoice bool     `json:",omitempty"`
+}
+
+// Game modes are flags, written out in this order
+var gameModeNames = []struct {
+	mode uint64
+	name string
+}{
+	{GMODE_CONST_STAKES, "CONST_STAKES"},
+	{GMODE_POT_LIMIT_OMAHA, "POT_LIMIT_OMAHA"},
+	{GMODE_SHORT_DECK, "SHORT_DECK"},
+	{GMODE_TRIPS_BEAT_STRAIGHTS, "TRIPS_BEAT_STRAIGHTS"},
+	{GMODE_HI_LO, "HI_LO"},
+	{GMODE_FIXED_LIMIT, "FIXED_LIMIT"},
+	{GMODE_SEVEN_CARD_STUD, "SEVEN_CARD_STUD"},
+	{GMODE_FIVE_CARD_DRAW, "FIVE_CARD_DRAW"},
+	{GMODE_TRIPLE_DRAW, "TRIPLE_DRAW"},
+	{GMODE_RAZZ, "RAZZ"},
+	{GMODE_DEUCES_WILD, "DEUCES_WILD"},
+	{GMODE_JOKERS_WILD, "JOKERS_WILD"},
 }
 
 func gameMode2Str(mode uint64) (string, error) {
-	if mode == GMODE_CONST_STAKES {
-		return "CONST_STAKES", nil
+	modes := make([]string, 0, len(gameModeNames))
+	for _, m := range gameModeNames {
+		if mode&m.mode > 0 {
+			modes = append(modes, m.name)
+			mode &= ^m.mode
+		}
 	}
-	return "", fmt.Errorf("Invalid game mode: %d", mode)
+	if mode != 0 || len(modes) == 0 {
+		return "", fmt.Errorf("Invalid game mode: %d", mode)
+	}
+	return strings.Join(modes, " "), nil
 }
 
-func str2GameMode(mode string) (uint64, error) {
-	if mode == "CONST_STAKES" {
-		return GMODE_CONST_STAKES, nil
+func str2GameMode(modeStr string) (uint64, error) {
+	var mode uint64
+	for _, s := range strings.Split(modeStr, " ") {
+		found := false
+		for _, m := range gameModeNames {
+			if s == m.name {
+				mode |= m.mode
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("Invalid game mode (str): %s", s)
+		}
 	}
-	return 0, fmt.Errorf("Invalid game mode (str): %d", mode)
+	return mode, nil
 }
 
 func gameStatus2Str(status uint64) (string, error) {
@@ -137,7 +229,79 @@ func str2GameStatus(statusStr string) (uint64, error) {
 	return status, nil
 }
 
+// Create a game; seven-card stud and draw games get their own GameLikes (on top of the same table)
+// and so do mixed games, which switch between them
 func New(creator *string, args *GameInitArgs) (*string, GameLike, error) {
+	creator, g, err := newGame(creator, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(g.games) > 0 {
+		return creator, newMixed(g, args), nil
+	}
+	return creator, variant(g), nil
+}
+
+// The GameLike that plays a game in its current mode
+func variant(g *Game) GameLike {
+	return variantFor(g, g.mode)
+}
+
+// The GameLike that plays a game in a mode
+func variantFor(g *Game, mode uint64) GameLike {
+	if mode&GMODE_SEVEN_CARD_STUD > 0 {
+		return &Stud{Game: g}
+	}
+	if mode&(GMODE_FIVE_CARD_DRAW|GMODE_TRIPLE_DRAW) > 0 {
+		return &Draw{Game: g}
+	}
+	return g
+}
+
+// Check that a mode can be played, returning it with whatever it implies (i.e. stud is fixed-limit)
+func checkMode(mode uint64) (uint64, error) {
+	supported := GMODE_CONST_STAKES | GMODE_POT_LIMIT_OMAHA | GMODE_SHORT_DECK | GMODE_TRIPS_BEAT_STRAIGHTS |
+		GMODE_HI_LO | GMODE_FIXED_LIMIT | GMODE_SEVEN_CARD_STUD | GMODE_FIVE_CARD_DRAW | GMODE_TRIPLE_DRAW | GMODE_RAZZ |
+		GMODE_DEUCES_WILD | GMODE_JOKERS_WILD
+	if mode & ^supported != 0 {
+		return 0, fmt.Errorf("Tried to create game with unsupported mode %d", mode)
+	}
+	if mode&GMODE_TRIPS_BEAT_STRAIGHTS > 0 && mode&GMODE_SHORT_DECK == 0 {
+		return 0, fmt.Errorf("Three of a kind can only beat straights in short-deck games")
+	}
+	if mode&GMODE_HI_LO > 0 && mode&GMODE_SHORT_DECK > 0 {
+		return 0, fmt.Errorf("Short-deck games cannot be hi/lo since nobody can make an eight-or-better low")
+	}
+	// Stud is played with fixed limits and has no room for Omaha's hole cards or a short deck
+	if mode&GMODE_SEVEN_CARD_STUD > 0 {
+		if mode&(GMODE_POT_LIMIT_OMAHA|GMODE_SHORT_DECK) > 0 {
+			return 0, fmt.Errorf("Seven-card stud cannot be played as Omaha or short-deck")
+		}
+		mode |= GMODE_FIXED_LIMIT
+	}
+	if mode&GMODE_RAZZ > 0 && (mode&GMODE_SEVEN_CARD_STUD == 0 || mode&GMODE_HI_LO > 0) {
+		return 0, fmt.Errorf("Razz is seven-card stud for the low only")
+	}
+	// Draw games have no board and triple draw is played fixed-limit
+	if draw := mode & (GMODE_FIVE_CARD_DRAW | GMODE_TRIPLE_DRAW); draw > 0 {
+		if draw == GMODE_FIVE_CARD_DRAW|GMODE_TRIPLE_DRAW {
+			return 0, fmt.Errorf("Games cannot be both five-card draw and triple draw")
+		}
+		if mode&(GMODE_POT_LIMIT_OMAHA|GMODE_SHORT_DECK|GMODE_HI_LO|GMODE_SEVEN_CARD_STUD) > 0 {
+			return 0, fmt.Errorf("Draw games cannot be played as Omaha, short-deck, hi/lo or stud")
+		}
+		if draw == GMODE_TRIPLE_DRAW {
+			mode |= GMODE_FIXED_LIMIT
+		}
+	}
+	// Wild cards only make high hands better (and a short deck has no twos)
+	if mode&(GMODE_DEUCES_WILD|GMODE_JOKERS_WILD) > 0 && mode&(GMODE_SHORT_DECK|GMODE_HI_LO|GMODE_TRIPLE_DRAW|GMODE_RAZZ) > 0 {
+		return 0, fmt.Errorf("Wild cards cannot be played short-deck or for the low")
+	}
+	return mode, nil
+}
+
+func newGame(creator *string, args *GameInitArgs) (*string, *Game, error) {
 	// Initialize all the settings using defaults if they don't provide any
 	joinCode := args.JoinCode
 	if joinCode == nil {
@@ -177,8 +341,75 @@ func New(creator *string, args *GameInitArgs) (*string, GameLike, error) {
 		startingChips = 10 * stakes
 	}
 
-	if args.Mode != 0 && args.Mode != DEFAULT_MODE {
-		return nil, nil, fmt.Errorf("Tried to create game with mode %d, but only mode %d supported", args.Mode, DEFAULT_MODE)
+	awayGrace := args.AwayGrace
+	if awayGrace == 0 {
+		awayGrace = DEFAULT_AWAY_GRACE
+	}
+
+	offerWindow := args.SeatOfferWindow
+	if offerWindow == 0 {
+		offerWindow = DEFAULT_SEAT_OFFER_WINDOW
+	}
+
+	maxSitOut := args.MaxSitOutOrbits
+	if maxSitOut == 0 {
+		maxSitOut = DEFAULT_MAX_SITOUT_ORBITS
+	}
+
+	straddle, found := straddleNames[args.Straddle]
+	if !found {
+		return nil, nil, fmt.Errorf("Tried to create game with unknown straddle %d", args.Straddle)
+	}
+
+	anteStructure, found := anteStructureNames[args.AnteStructure]
+	if !found {
+		return nil, nil, fmt.Errorf("Tried to create game with unknown ante structure %d", args.AnteStructure)
+	}
+
+	ante := args.Ante
+	if ante == 0 && args.AnteStructure == ANTE_BIG_BLIND {
+		ante = stakes
+	} else if ante == 0 {
+		ante = stakes / 10
+	}
+
+	bombPotAnte := args.BombPotAnte
+	if bombPotAnte == 0 {
+		bombPotAnte = stakes
+	}
+
+	// Stakes are always constant and mixed games start with the first of their games
+	mode, err := checkMode(args.Mode | DEFAULT_MODE)
+	if err != nil {
+		return nil, nil, err
+	}
+	games := make([]uint64, len(args.Games))
+	gameNames := make([]string, len(args.Games))
+	for i, m := range args.Games {
+		if games[i], err = checkMode(m | DEFAULT_MODE); err != nil {
+			return nil, nil, err
+		}
+		gameNames[i], _ = gameMode2Str(games[i])
+	}
+	for _, m := range append(games, mode) {
+		if args.BombPotEvery > 0 && m&(GMODE_FIVE_CARD_DRAW|GMODE_TRIPLE_DRAW) > 0 {
+			return nil, nil, fmt.Errorf("Draw games cannot have bomb pots since there is no board")
+		}
+		if maxPlayers > MAX_STUD_PLAYERS && m&GMODE_SEVEN_CARD_STUD > 0 {
+			return nil, nil, fmt.Errorf("Seven-card stud can be played by at most %d players", MAX_STUD_PLAYERS)
+		}
+	}
+	if len(games) > 0 {
+		mode = games[0]
+	}
+
+	language := args.Language
+	if language == "" {
+		language = DEFAULT_LANGUAGE
+	}
+	locale, ok := Locales[language]
+	if !ok {
+		return nil, nil, fmt.Errorf("Hands cannot be described in unknown language %q", language)
 	}
 
 	// Create directory with game information
@@ -210,9 +441,9 @@ func New(creator *string, args *GameInitArgs) (*string, GameLike, error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("Failed to convert status %d to string: `%v`", status, err)
 	}
-	gm, err := gameMode2Str(DEFAULT_MODE)
+	gm, err := gameMode2Str(mode)
 	if err != nil {
-		return nil, nil, fmt.Errorf("Failed to convert mode %d to string: `%v`", DEFAULT_MODE, err)
+		return nil, nil, fmt.Errorf("Failed to convert mode %d to string: `%v`", mode, err)
 	}
 	m, err := json.MarshalIndent(gameInitJson{
 		Id:            fmt.Sprintf("%d", id),
@@ -225,6 +456,18 @@ func New(creator *string, args *GameInitArgs) (*string, GameLike, error) {
 		Mode:          gm,
 		KeepPlayers:   args.KeepPlayers,
 		KeepChips:     args.KeepChips,
+		AwayGrace:     awayGrace.String(),
+		OfferWindow:   offerWindow.String(),
+		TVDelay:       args.TVDelay.String(),
+		MaxSitOut:     maxSitOut,
+		Straddle:      straddle,
+		AnteStructure: anteStructure,
+		Ante:          ante,
+		BombPotAnte:   bombPotAnte,
+		BombPotEvery:  args.BombPotEvery,
+		Games:         gameNames,
+		HandsPerGame:  args.HandsPerGame,
+		DealersChoice: args.DealersChoice,
 	}, "", "  ")
 	if err != nil {
 		return nil, nil, fmt.Errorf("Failed to marshal game init args: `%v`", err)
@@ -244,13 +487,30 @@ func New(creator *string, args *GameInitArgs) (*string, GameLike, error) {
 		joinCode:      joinCode,
 		name:          name, 
 		middle:        [5]Card{NoCards, NoCards, NoCards, NoCards, NoCards},
+		deck:          newCardSetLike(NoCards), // Filled at the start of every round
+		muck:          newCardSetLike(NoCards),
 		Id:            id, // A random id
 		maxPlayers:    maxPlayers,        // As above (zero is infinite)
 		players:       nil,               // Initialized lazily as we add players
 		status:        status,            // Status 0 simply is a negation of all statuses
-		mode:          DEFAULT_MODE,      // Rake not yet supported
+		mode:          mode,              // Rake not yet supported
 		stakes:        stakes,            // ...
 		startingChips: startingChips,     // ...
+		button:        -1,                // The first round gives the button to the first seat
+		turn:          -1,                // Nobody can act until a round starts
+		awayGrace:     awayGrace,
+		offerWindow:   offerWindow,
+		tvDelay:       args.TVDelay,
+		maxSitOut:     maxSitOut,
+		straddle:      args.Straddle,
+		anteStructure: args.AnteStructure,
+		ante:          ante,
+		bombPotAnte:   bombPotAnte,
+		bombPotEvery:  args.BombPotEvery,
+		games:         games,
+		locale:        locale,
+		now:           time.Now,
+		rng:           rand.New(rand.NewSource(int64(utils.RandInt64()))),
 		gameDir:       &gameDir,
 		errorLog:      errorLog,
 		roundLog:      roundLog,
@@ -264,10 +524,11 @@ func New(creator *string, args *GameInitArgs) (*string, GameLike, error) {
 	if !added || err != nil {
 		return nil, nil, fmt.Errorf("Failed to add (added = %v) creator `%s`: `%v`", added, *creator, err)
 	}
-	modded, err := g.ModPlayer(nil, creator, PSTATUS_ADMIN)
-	if !modded || err != nil {
-		return nil, nil, fmt.Errorf("Failed to mod (modded=%v) creator `%s` to admin status: `%v`", modded, *creator, err)
+	admin, found := g.getPlayer(creator)
+	if !found {
+		return nil, nil, fmt.Errorf("Failed to find creator `%s` to make them admin", *creator)
 	}
+	admin.Status |= PSTATUS_ADMIN
 
 	return creator, g, nil
 }
@@ -327,35 +588,105 @@ func (g *Game) AddPlayer(name *string, joinCode *string) (*string, bool, error)
 	if !g.Private() && g.joinCode == nil {
 		return nil, false, fmt.Errorf("Trying to add to a nil joincode game that is private")
 	}
-	if g.Private() && (joinCode != nil || *joinCode != *g.joinCode) {
+	if g.Private() && (joinCode == nil || *joinCode != *g.joinCode) {
 		return nil, false, nil
 	}
 	if name == nil {
 		n := randPlayerName()
 		name = &n
 	}
+	if g.waitlistIndex(name) >= 0 {
+		return nil, false, fmt.Errorf("Player with name %s is already waiting for a seat\n", *name)
+	}
+	// Nobody jumps the queue; players get seats in the order they asked for them
+	if g.tableFull() || len(g.waitlist) > 0 {
+		if _, found := g.getPlayer(name); found {
+			return nil, false, fmt.Errorf("Player with name %s exists\n", *name)
+		}
+		g.waitlist = append(g.waitlist, &waitlistEntry{name: name})
+		g.roundLogger.Printf("%s is waiting for a seat\n", *name)
+		g.offerSeats()
+		return name, false, nil
+	}
+	p, err := g.seatPlayer(name)
+	if err != nil {
+		return nil, false, err
+	}
+	return p.Name, true, nil
+}
+
+// Create a player with the starting stack and give them the last seat
+func (g *Game) seatPlayer(name *string) (*Player, error) {
 	p := &Player{
-		Hand:   [2]Card{NoCards, NoCards},
+		Hand:   g.emptyHand(),
 		Chips:  g.startingChips,
 		Status: 0, // Not yet playing
 		Id:     utils.RandInt64(),
 		Name:   name,
 		GameId: g.Id,
 	}
+	// Once the game has started newcomers post a big blind to be dealt in or wait for it
+	if g.roundNum > 0 {
+		p.owesBig = true
+		p.Status |= PSTATUS_WAIT_FOR_BB
+	}
 	if g.players != nil {
 		for _, player := range g.players {
 			if p.Id == player.Id {
-				return nil, false, fmt.Errorf("Player with id %d exists\n", p.Id)
+				return nil, fmt.Errorf("Player with id %d exists\n", p.Id)
 			}
-			if p.Name == player.Name {
-				return nil, false, fmt.Errorf("Player with name %s exists\n", name)
+			if *p.Name == *player.Name {
+				return nil, fmt.Errorf("Player with name %s exists\n", *name)
 			}
 		}
 	} else {
 		g.players = make(map[uint64]*Player, 1)
 	}
 	g.players[p.Id] = p
-	return p.Name, true, nil
+	g.seats = append(g.seats, p.Id)
+	// Spectators who take a seat are no longer on the rail
+	if i := g.spectatorIndex(name); i >= 0 {
+		g.spectators = append(g.spectators[:i], g.spectators[i+1:]...)
+	}
+	return p, nil
+}
+
+// Mark a player as away; they keep their seat and stack until the grace period runs out
+// and in the meantime check when they can and fold when they can't
+func (g *Game) Disconnect(name *string) (bool, error) {
+	p, found := g.getPlayer(name)
+	if !found {
+		return false, fmt.Errorf("Could not find disconnected player %s", *name)
+	}
+	if p.Status&PSTATUS_AWAY > 0 {
+		return false, nil
+	}
+	p.Status |= PSTATUS_AWAY
+	p.AwaySince = g.now()
+	g.roundLogger.Printf("%s is away\n", *p.Name)
+	if g.turn >= 0 && g.seats[g.turn] == p.Id {
+		g.actForAway(p)
+		g.advanceTurn()
+	}
+	return true, nil
+}
+
+// Return an away player to their seat (if the grace period has not yet run out)
+func (g *Game) Reconnect(name *string) (bool, error) {
+	p, found := g.getPlayer(name)
+	if !found {
+		return false, fmt.Errorf("Could not find reconnecting player %s", *name)
+	}
+	if p.Status&PSTATUS_AWAY == 0 {
+		return false, nil
+	}
+	if g.now().Sub(p.AwaySince) >= g.awayGrace {
+		return false, fmt.Errorf("%s was away for longer than %v and lost their seat", *p.Name, g.awayGrace)
+	}
+	p.Status &= ^PSTATUS_AWAY
+	p.AwaySince = time.Time{}
+	g.roundLogger.Printf("%s is back\n", *p.Name)
+	return true, nil
 }
 
 func (g *Game) KickPlayer(kicker *string, kicked *string) (bool, error) {
@@ -364,12 +695,56 @@ func (g *Game) KickPlayer(kicker *string, kicked *string) (bool, error) {
 		if !found {
 			return false, fmt.Errorf("Tried to kick nonexistent player %s", *kicked)
 		}
-		// FIXME: add some checking for whether the game is in play or not (etc)
-		delete(g.players, rec.Id)
+		if rec.Status&PSTATUS_LEAVING > 0 {
+			return false, nil
+		}
+		g.leave(rec)
 		return true, nil
 	})
 }
 
+func (g *Game) LeavePlayer(name *string) (bool, error) {
+	p, found := g.getPlayer(name)
+	if !found {
+		return false, fmt.Errorf("Could not find leaving player %s", *name)
+	}
+	if p.Status&PSTATUS_LEAVING > 0 {
+		return false, nil
+	}
+	g.leave(p)
+	return true, nil
+}
+
+// Record what is left of a player's stack and take it off the table
+func (g *Game) cashOut(p *Player) {
+	g.cashOuts = append(g.cashOuts, CashOut{Name: *p.Name, Chips: p.Chips, Round: g.roundNum})
+	g.roundLogger.Printf("%s cashes out %d chips\n", *p.Name, p.Chips)
+	p.Chips = 0
+}
+
+// A player who leaves in the middle of a round folds and forfeits whatever they have bet, but
+// keeps their seat until the end of the round so that the order of play does not change under
+// everyone's feet. Their stack is cashed out right away either way.
+func (g *Game) leave(p *Player) {
+	g.cashOut(p)
+	if !g.handInProgress() || p.Status&PSTATUS_PLAYING == 0 {
+		g.removePlayer(p.Id)
+		return
+	}
+	p.Status |= PSTATUS_LEAVING
+	if inHand(p) {
+		p.Status |= PSTATUS_FOLDED
+		g.roundLogger.Printf("%s folds and leaves\n", *p.Name)
+	}
+	if g.turn >= 0 && (g.seats[g.turn] == p.Id || g.bettingDone()) {
+		g.advanceTurn()
+	}
+}
+
+func (g *Game) CashOuts() []CashOut {
+	return append([]CashOut{}, g.cashOuts...)
+}
+
 // Change the status of a player by id or name
 func (g *Game) ModPlayer(modder *string, modded *string, mod uint64) (bool, error){
 	return g.onlyExecuteIfIsAdmin(modder, func() (bool, error) {
@@ -378,7 +753,8 @@ func (g *Game) ModPlayer(modder *string, modded *string, mod uint64) (bool, erro
 			return false, fmt.Errorf("Did not find player %s to mod", *modded)
 		}
 		// We may want to change this to add and remove permissions later
-		rec.Status = mod
+		// (only the permission bits are touched so that game flow statuses survive)
+		rec.Status = (rec.Status & ^PPERM_ADMIN) | (mod & PPERM_ADMIN)
 		return true, nil
 	})
 }
@@ -402,7 +778,7 @@ func (g *Game) ChangePlayerName(changer *string, name *string, newName *string)
 func (g *Game) ChangeGameName(changer *string, name *string) (bool, error) {
 	return g.onlyExecuteIfIsAdmin(changer, func() (bool, error) {
 		if name == nil {
-			n := fmt.Sprintf("%s-game-%s", changer)
+			n := fmt.Sprintf("%s-game-%s", utils.RandAdjAnimal(nil), utils.RandString(3))
 			name = &n
 		}
 		g.name = name
@@ -444,12 +820,19 @@ func (g *Game) Private() bool {
 	return g.status & GSTATUS_PRIVATE > 0
 }
 
+func (g *Game) Mode() uint64 {
+	return g.mode
+}
+
 func (g *Game) Players() []*PlayerInfo {
-	players := make([]*PlayerInfo, 0, len(g.players))
-	for _, p := range g.players {
-		var c [2]CardLike
+	players := make([]*PlayerInfo, 0, len(g.seats))
+	for _, id := range g.seats {
+		p := g.players[id]
+		c := make([]CardLike, len(p.Hand))
+		up := make([]bool, len(p.Hand))
 		for i, _ := range p.Hand {
 			c[i] = p.Hand[i]
+			up[i] = i < len(p.up) && p.up[i]
 		}
 		players = append(players, &PlayerInfo{
 			Name:  *p.Name,
@@ -457,7 +840,12 @@ func (g *Game) Players() []*PlayerInfo {
 			Bet:   p.Bet,
 			Id:    p.Id,
 			Cards: c,
+			Up:    up,
 			Mod:   (p.Status & PSTATUS_ADMIN) > 0,
+			Away:  (p.Status & PSTATUS_AWAY) > 0,
+
+			SittingOut: (p.Status & (PSTATUS_SITTING_OUT | PSTATUS_WAIT_FOR_BB)) > 0,
+			OwesBlinds: p.owesBig || p.owesSmall,
 		})
 	}
 	return players
@@ -499,14 +887,584 @@ func (g *Game) MakePublic(publicer *string) (bool, error) {
 
 //////////////////////////////////////////////////////////////////// Game flow functionality
 
+func (g *Game) seated(seat int) *Player {
+	return g.players[g.seats[seat]]
+}
+
+func (g *Game) seatOf(id uint64) int {
+	for i, sid := range g.seats {
+		if sid == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Return the first seat after from (going around the table) whose player satisfies the
+// predicate or -1 if there is no such seat
+func (g *Game) nextSeat(from int, pred func(*Player) bool) int {
+	n := len(g.seats)
+	for k := 1; k <= n; k++ {
+		i := (from + k) % n
+		if pred(g.seated(i)) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *Game) handInProgress() bool {
+	return g.bettingRound != 0
+}
+
+// A player is in the hand if they were dealt in and have not folded
+func inHand(p *Player) bool {
+	return p.Status&PSTATUS_PLAYING > 0 && p.Status&PSTATUS_FOLDED == 0
+}
+
+// A player can act if they are in the hand and have chips behind
+func canAct(p *Player) bool {
+	return inHand(p) && p.Status&PSTATUS_ALL_IN == 0
+}
+
+func (g *Game) needsToAct(p *Player) bool {
+	// Everyone in the hand draws, even if they are all in
+	if g.drawing {
+		return inHand(p) && !p.acted
+	}
+	return canAct(p) && (!p.acted || p.Bet < g.currentBet)
+}
+
+func (g *Game) liveCount() int {
+	live := 0
+	for _, id := range g.seats {
+		if inHand(g.players[id]) {
+			live++
+		}
+	}
+	return live
+}
+
+// The betting round is over when everyone who can still act has acted and matched the
+// largest bet, or when there is nobody left to bet against
+func (g *Game) bettingDone() bool {
+	if g.liveCount() <= 1 {
+		return true
+	}
+	// A draw is over when everyone in the hand has drawn
+	if g.drawing {
+		for _, id := range g.seats {
+			if g.needsToAct(g.players[id]) {
+				return false
+			}
+		}
+		return true
+	}
+	actors := 0
+	for _, id := range g.seats {
+		p := g.players[id]
+		if !canAct(p) {
+			continue
+		}
+		if p.Bet < g.currentBet {
+			return false
+		}
+		actors++
+	}
+	if actors <= 1 {
+		return true
+	}
+	for _, id := range g.seats {
+		if g.needsToAct(g.players[id]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Pass the turn to the next player who needs to act, acting on behalf of away players
+func (g *Game) advanceTurn() {
+	for {
+		if g.bettingDone() {
+			g.turn = -1
+			return
+		}
+		g.turn = g.nextSeat(g.turn, g.needsToAct)
+		if g.turn < 0 {
+			return
+		}
+		p := g.seated(g.turn)
+		if p.Status&PSTATUS_AWAY == 0 {
+			return
+		}
+		g.actForAway(p)
+	}
+}
+
+// Away players check when they can and fold otherwise (and stand pat in draws)
+func (g *Game) actForAway(p *Player) {
+	if g.drawing {
+		g.move(p, MTYPE_DRAW, 0)
+		return
+	}
+	if p.Bet >= g.currentBet {
+		g.move(p, MTYPE_CHECK, 0)
+	} else {
+		g.move(p, MTYPE_FOLD, 0)
+	}
+}
+
+// A player with no cards (two in hold'em, four in Omaha and seven in stud)
+func (g *Game) emptyHand() []Card {
+	n := 2
+	if g.mode&GMODE_POT_LIMIT_OMAHA > 0 {
+		n = 4
+	} else if g.mode&GMODE_SEVEN_CARD_STUD > 0 {
+		n = 7
+	} else if g.mode&(GMODE_FIVE_CARD_DRAW|GMODE_TRIPLE_DRAW) > 0 {
+		n = 5
+	}
+	hand := make([]Card, n)
+	for i := range hand {
+		hand[i] = NoCards
+	}
+	return hand
+}
+
+// The size of a bet (and of every raise) in fixed-limit games, which doubles after the
+// second betting round, and the smallest bet in other games
+func (g *Game) betUnit() uint64 {
+	if g.mode&GMODE_FIXED_LIMIT > 0 && g.bettingRound > BROUND_FLOP {
+		return 2 * g.stakes
+	}
+	return g.stakes
+}
+
+// The betting round after which hands go to showdown
+func (g *Game) lastRound() uint64 {
+	if g.mode&GMODE_SEVEN_CARD_STUD > 0 {
+		return BROUND_SEVENTH_STREET
+	}
+	// Five-card draw has a betting round before and after the draw
+	if g.mode&GMODE_FIVE_CARD_DRAW > 0 {
+		return BROUND_FLOP
+	}
+	return BROUND_RIVER
+}
+
+// Under pot limit the most a player can bet to is a call followed by a raise of the whole pot
+// (counting the call)
+func (g *Game) potLimit(p *Player) uint64 {
+	var pot uint64 = 0
+	for _, id := range g.seats {
+		pot += g.players[id].Pot + g.players[id].Bet
+	}
+	call := g.currentBet - p.Bet
+	return g.currentBet + pot + call
+}
+
+// Move chips from a player's stack into their bet (up to all of their chips)
+func (g *Game) putIn(p *Player, chips uint64) uint64 {
+	if chips >= p.Chips {
+		chips = p.Chips
+		p.Status |= PSTATUS_ALL_IN
+	}
+	p.Chips -= chips
+	p.Bet += chips
+	return chips
+}
+
+// Deal a random card from the deck (which is only empty if too many players are dealt in)
+func (g *Game) dealCard() (Card, error) {
+	if g.deck.Count() == 0 {
+		return Card(NoCards), fmt.Errorf("Ran out of cards in round %d", g.roundNum)
+	}
+	card := g.deck.Nth(g.rng.Intn(g.deck.Count()))
+	g.deck = g.deck.Without(card)
+	return card, nil
+}
+
+// Put every card back in the deck (without the twos through fives in short-deck)
+func (g *Game) newDeck() {
+	var deck CardSet = AllCards
+	if g.mode&GMODE_SHORT_DECK > 0 {
+		deck = ShortDeck
+	}
+	if g.mode&GMODE_JOKERS_WILD > 0 {
+		deck |= Jokers
+	}
+	g.deck, g.muck = newCardSetLike(deck), newCardSetLike(NoCards)
+}
+
+// Apply a single move for a player whose turn it is
+func (g *Game) move(p *Player, move uint64, chips uint64) (bool, error) {
+	if g.drawing && move != MTYPE_DRAW {
+		return false, fmt.Errorf("%s has to draw (or stand pat) before the betting goes on", *p.Name)
+	}
+	switch move {
+	case MTYPE_DRAW:
+		if !g.drawing {
+			return false, fmt.Errorf("%s cannot draw during a betting round", *p.Name)
+		}
+		if drew, err := g.draw(p, CardSet(chips)); !drew {
+			return false, err
+		}
+	case MTYPE_CHECK:
+		if p.Bet < g.currentBet {
+			return false, fmt.Errorf("%s cannot check facing a bet of %d", *p.Name, g.currentBet)
+		}
+		g.roundLogger.Printf("%s checks\n", *p.Name)
+	case MTYPE_FOLD:
+		p.Status |= PSTATUS_FOLDED
+		g.roundLogger.Printf("%s folds\n", *p.Name)
+	case MTYPE_CALL, MTYPE_CALL_ANY:
+		called := g.putIn(p, g.currentBet-p.Bet)
+		g.roundLogger.Printf("%s calls %d\n", *p.Name, called)
+	case MTYPE_BET:
+		if chips == 0 || chips > p.Chips {
+			return false, fmt.Errorf("%s cannot bet %d with %d chips", *p.Name, chips, p.Chips)
+		}
+		total := p.Bet + chips
+		if chips < p.Chips && total < g.currentBet+g.minRaise {
+			return false, fmt.Errorf("%s must bet to at least %d (or go all in)", *p.Name, g.currentBet+g.minRaise)
+		}
+		if g.mode&GMODE_FIXED_LIMIT > 0 && g.raises >= MAX_LIMIT_BETS {
+			return false, fmt.Errorf("Betting is capped at %d bets", MAX_LIMIT_BETS)
+		}
+		if g.mode&GMODE_FIXED_LIMIT > 0 && chips < p.Chips && total != g.currentBet+g.minRaise {
+			return false, fmt.Errorf("%s must bet to exactly %d (the limit)", *p.Name, g.currentBet+g.minRaise)
+		}
+		if g.mode&GMODE_POT_LIMIT_OMAHA > 0 && g.mode&GMODE_FIXED_LIMIT == 0 && total > g.potLimit(p) {
+			return false, fmt.Errorf("%s can bet to at most %d (the pot limit)", *p.Name, g.potLimit(p))
+		}
+		if total > g.currentBet {
+			if total-g.currentBet > g.minRaise {
+				g.minRaise = total - g.currentBet
+			}
+			if g.mode&GMODE_FIXED_LIMIT > 0 {
+				g.minRaise = g.betUnit()
+			}
+			g.currentBet = total
+			g.raises++
+			// Everyone else has to respond to the raise
+			for _, id := range g.seats {
+				g.players[id].acted = false
+			}
+		}
+		g.putIn(p, chips)
+		g.roundLogger.Printf("%s bets %d to %d\n", *p.Name, chips, total)
+	default:
+		return false, fmt.Errorf("Unknown move %d", move)
+	}
+	p.acted = true
+	return true, nil
+}
+
+// Moves that are batched are tried by precedence (lowest value first) until one is legal,
+// so for example MTYPE_CHECK | MTYPE_FOLD checks when possible and folds otherwise
+// (sitting out is planned for the next round so it can be batched with anything at any time)
+var movePrecedence = []uint64{MTYPE_CHECK, MTYPE_FOLD, MTYPE_CALL, MTYPE_CALL_ANY, MTYPE_BET, MTYPE_DRAW}
+
 // Attempt to make a move with some chips; chips are ignored for checks and folds
 func (g *Game) Move(move uint64, chips uint64, mover *string) (bool, error) {
-	// TODO
-	return false, nil
+	if move&MTYPE_FOLD > 0 && move&(MTYPE_CALL|MTYPE_CALL_ANY|MTYPE_BET) > 0 {
+		return false, fmt.Errorf("Cannot fold and call or bet at the same time (move %d)", move)
+	}
+	p, found := g.getPlayer(mover)
+	if !found {
+		return false, fmt.Errorf("Could not find player %s to move", *mover)
+	}
+	if move&MTYPE_SITOUT_NEXT_ROUND > 0 {
+		g.sitOut(p)
+		move &= ^MTYPE_SITOUT_NEXT_ROUND
+		if move == 0 {
+			return true, nil
+		}
+	}
+	if !g.Playing() {
+		return false, fmt.Errorf("Cannot move while the game is paused")
+	}
+	if !g.handInProgress() {
+		return false, fmt.Errorf("Cannot move when no round is in progress")
+	}
+	if g.turn < 0 || g.seats[g.turn] != p.Id {
+		return false, nil
+	}
+
+	var err error
+	for _, m := range movePrecedence {
+		if move&m == 0 {
+			continue
+		}
+		var moved bool
+		moved, err = g.move(p, m, chips)
+		if moved {
+			g.advanceTurn()
+			return true, nil
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("Unknown move %d", move)
+	}
+	return false, err
 }
 
+// Deal the next street once the betting round is over
 func (g *Game) Increment() (bool, error) {
-	return false, nil // TODO
+	if !g.handInProgress() {
+		return false, fmt.Errorf("Cannot increment when no round is in progress")
+	}
+	if !g.bettingDone() || g.liveCount() <= 1 || g.bettingRound == BROUND_RIVER {
+		return false, nil
+	}
+	// Boards that are run more than once are dealt all at once by Resolve
+	if g.runItTimes() > 1 {
+		return false, nil
+	}
+	g.collectBets()
+
+	var street []int
+	switch g.bettingRound {
+	case BROUND_PREFLOP:
+		street = []int{0, 1, 2}
+	case BROUND_FLOP:
+		street = []int{3}
+	case BROUND_TURN:
+		street = []int{4}
+	}
+	for _, i := range street {
+		card, err := g.dealCard()
+		if err != nil {
+			return false, err
+		}
+		g.middle[i] = card
+	}
+	g.bettingRound++
+	g.roundLogger.Printf("Betting round %d: %s\n", g.bettingRound, g.middleCards().String())
+
+	g.currentBet = 0
+	g.minRaise = g.betUnit()
+	g.raises = 0
+	for _, id := range g.seats {
+		g.players[id].acted = false
+	}
+	// Post-flop the first player left of the button acts first
+	g.turn = g.button
+	g.advanceTurn()
+	return true, nil
+}
+
+func (g *Game) middleCards() Card {
+	var middle Card = NoCards
+	for _, c := range g.middle {
+		middle |= c
+	}
+	return middle
+}
+
+// Move every bet into the pots, returning any part of a bet that nobody called
+func (g *Game) collectBets() {
+	var highest, second uint64
+	var highestPlayer *Player
+	for _, id := range g.seats {
+		p := g.players[id]
+		if p.Bet > highest {
+			second = highest
+			highest = p.Bet
+			highestPlayer = p
+		} else if p.Bet > second {
+			second = p.Bet
+		}
+	}
+	// Folded players forfeit their bets (i.e. when they leave after betting)
+	if highestPlayer != nil && highest > second && inHand(highestPlayer) {
+		highestPlayer.Chips += highest - second
+		highestPlayer.Bet = second
+		if highestPlayer.Chips > 0 {
+			highestPlayer.Status &= ^PSTATUS_ALL_IN
+		}
+	}
+	for _, id := range g.seats {
+		p := g.players[id]
+		p.Pot += p.Bet
+		p.Bet = 0
+	}
+	g.pots = g.buildPots()
+}
+
+// Build the main pot and side pots from how much each player has put in this round.
+// Every distinct all-in amount caps a pot that only players who put in at least that much
+// can win. Chips from folded players stay in the pots but they cannot win them.
+func (g *Game) buildPots() []Pot {
+	levels := make([]uint64, 0, len(g.seats))
+	for _, id := range g.seats {
+		if p := g.players[id]; p.Pot > 0 && inHand(p) {
+			levels = append(levels, p.Pot)
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+
+	pots := make([]Pot, 0, len(levels))
+	var prev uint64 = 0
+	for i, level := range levels {
+		if level == prev {
+			continue
+		}
+		// The highest level must also collect the folded chips above it
+		last := i == len(levels)-1
+		pot := Pot{}
+		for _, id := range g.seats {
+			p := g.players[id]
+			if p.Pot > prev {
+				if p.Pot < level || last {
+					pot.Chips += p.Pot - prev
+				} else {
+					pot.Chips += level - prev
+				}
+			}
+			if p.Pot >= level && inHand(p) {
+				pot.Players = append(pot.Players, p.Id)
+			}
+		}
+		// Players who can win a pot can win every pot below it, so pots with as
+		// many players as the one before are really the same pot
+		if len(pots) > 0 && len(pots[len(pots)-1].Players) == len(pot.Players) {
+			pots[len(pots)-1].Chips += pot.Chips
+		} else {
+			pots = append(pots, pot)
+		}
+		prev = level
+	}
+	return pots
+}
+
+// return a player's hole cards and the board as the sets the game deals from, which is what the
+// evaluators are given
+func (g *Game) handSets(p *Player, board Card) (CardSetLike, CardSetLike) {
+	var hole Card = NoCards
+	for _, c := range p.Hand {
+		hole |= c
+	}
+	return newCardSetLike(CardSet(hole)), newCardSetLike(CardSet(board))
+}
+
+// Return how hands are scored in the current mode: the evaluator that values a player's best hand
+// from their hole cards and the board, and the words that describe its values at showdown (low
+// games are won by lows)
+func (g *Game) scoring() (func(hole CardSetLike, board CardSetLike) uint64, func(uint64) string) {
+	evaluate := Evaluate
+	if g.mode&GMODE_SHORT_DECK > 0 {
+		tripsBeatStraights := g.mode&GMODE_TRIPS_BEAT_STRAIGHTS > 0
+		evaluate = func(cards CardSetLike) uint64 { return EvaluateShortDeck(cards, tripsBeatStraights) }
+	}
+	if wild := g.wildCards(); wild != NoCards {
+		evaluate = func(cards CardSetLike) uint64 { return EvaluateWild(cards, wild) }
+	}
+	if g.mode&GMODE_POT_LIMIT_OMAHA > 0 {
+		return func(hole CardSetLike, board CardSetLike) uint64 {
+			return evaluateOmaha(hole.Bits(), board.Bits(), func(cardset CardSet) uint64 {
+				return evaluate(newCardSetLike(cardset))
+			})
+		}, g.locale.Describe
+	}
+	if g.mode&GMODE_TRIPLE_DRAW > 0 {
+		return func(hole CardSetLike, board CardSetLike) uint64 {
+			return EvaluateLow27(hole)
+		}, g.locale.DescribeLow
+	}
+	if g.mode&GMODE_RAZZ > 0 {
+		return func(hole CardSetLike, board CardSetLike) uint64 {
+			return EvaluateLowA5(newCardSetLike(hole.Bits() | board.Bits()))
+		}, g.locale.DescribeLow
+	}
+	return func(hole CardSetLike, board CardSetLike) uint64 {
+		return evaluate(newCardSetLike(hole.Bits() | board.Bits()))
+	}, g.locale.Describe
+}
+
+// Return the value of a player's best hand given a board
+func (g *Game) handValue(p *Player, board Card) uint64 {
+	evaluate, _ := g.scoring()
+	return evaluate(g.handSets(p, board))
+}
+
+// Describe a player's hand value for the showdown message the way it was scored
+func (g *Game) describe(value uint64) string {
+	_, describe := g.scoring()
+	return describe(value)
+}
+
+// Return the value of a player's best eight-or-better low given a board (zero if they have no low
+// or the game is not hi/lo)
+func (g *Game) lowValue(p *Player, board Card) uint64 {
+	if g.mode&GMODE_HI_LO == 0 {
+		return 0
+	}
+	hole, middle := g.handSets(p, board)
+	if g.mode&GMODE_POT_LIMIT_OMAHA > 0 {
+		return EvaluateOmahaLowEight(hole, middle)
+	}
+	return EvaluateLowEight(newCardSetLike(hole.Bits() | middle.Bits()))
+}
+
+// Pay out every pot to its best hands on the middle and return a line describing each payout
+func (g *Game) awardPots() []string {
+	lines := make([]string, 0, len(g.pots))
+	for i, pot := range g.pots {
+		lines = append(lines, g.awardPot(i, pot.Players, pot.Chips, g.middleCards(), 0)...)
+	}
+	return lines
+}
+
+// Pay out chips from pot i to the best hands on the board (splitting ties, with odd chips going
+// to the earliest winners left of the button, and splitting hi/lo pots with the best low). Run is
+// which runout of the board this is (zero when the board is only run once).
+func (g *Game) awardPot(i int, players []uint64, chips uint64, board Card, run int) []string {
+	lines := make([]string, 0, 1)
+	showdown := g.liveCount() > 1
+	on := ""
+	if run > 0 {
+		on = fmt.Sprintf(" on run %d", run)
+	}
+	hands := make([]*Player, 0, len(players))
+	highs := make([]uint64, 0, len(players))
+	lows := make([]uint64, 0, len(players))
+	for k := 1; k <= len(g.seats); k++ {
+		p := g.seated((g.button + k) % len(g.seats))
+		if !inHand(p) || !containsId(players, p.Id) {
+			continue
+		}
+		hands = append(hands, p)
+		highs = append(highs, g.handValue(p, board))
+		lows = append(lows, g.lowValue(p, board))
+	}
+	split := SplitHiLo(chips, highs, lows)
+	for j, w := range hands {
+		if split.High[j] > 0 {
+			w.Chips += split.High[j]
+			if !showdown {
+				lines = append(lines, fmt.Sprintf("%s wins %d chips from pot %d", *w.Name, split.High[j], i))
+			} else if g.mode&GMODE_HI_LO > 0 {
+				lines = append(lines, fmt.Sprintf("%s wins %d chips from pot %d%s with %s for high", *w.Name, split.High[j], i, on, g.describe(highs[j])))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s wins %d chips from pot %d%s with %s", *w.Name, split.High[j], i, on, g.describe(highs[j])))
+			}
+		}
+		if split.Low[j] > 0 {
+			w.Chips += split.Low[j]
+			lines = append(lines, fmt.Sprintf("%s wins %d chips from pot %d%s with %s", *w.Name, split.Low[j], i, on, g.locale.DescribeLow(lows[j])))
+		}
+	}
+	return lines
+}
+
+func containsId(ids []uint64, id uint64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
 }
 
 // Resolve the winners from the current middle and those playing
@@ -514,15 +1472,196 @@ func (g *Game) Resolve() (*string, error) {
 	// NOTE: resolve should be able to handle different pots and all ins (incl. forced all ins)
 	// Moreover, a game has to remember player order, it has to be able to deal with betting rounds within
 	// game rounds within the game itself, etc...
+	if !g.handInProgress() {
+		return nil, fmt.Errorf("Cannot resolve when no round is in progress")
+	}
+	if !g.bettingDone() {
+		return nil, fmt.Errorf("Cannot resolve before betting round %d is over", g.bettingRound)
+	}
+	times := g.runItTimes()
+	if g.liveCount() > 1 && g.bettingRound != g.lastRound() && times == 1 {
+		return nil, fmt.Errorf("Cannot resolve before the river is dealt (betting round is %d)", g.bettingRound)
+	}
+	g.collectBets()
+	var msg string
+	if times > 1 {
+		lines, err := g.runItOut(times)
+		if err != nil {
+			return nil, err
+		}
+		msg = strings.Join(lines, "\n")
+	} else {
+		msg = strings.Join(g.awardPots(), "\n")
+	}
+	g.roundLogger.Printf("Round %d: %s\n", g.roundNum, msg)
 
-	// TODO
-	return nil, nil
+	g.endRound()
+	return &msg, nil
+}
+
+func (g *Game) endRound() {
+	g.bettingRound = 0
+	g.turn = -1
+	g.pots = nil
+	g.runItVotes = nil
+	g.drawing = false
+	for _, id := range append([]uint64{}, g.seats...) {
+		p := g.players[id]
+		if p.Status&PSTATUS_LEAVING > 0 {
+			g.removePlayer(id)
+			continue
+		}
+		p.Bet = 0
+		p.Pot = 0
+		p.Status &= ^(PSTATUS_PLAYING | PSTATUS_FOLDED | PSTATUS_ALL_IN)
+	}
+	g.removeExpired()
+}
+
+// Take a player out of the game and their seat out of the order of play
+func (g *Game) removePlayer(id uint64) {
+	seat := g.seatOf(id)
+	if seat >= 0 {
+		g.seats = append(g.seats[:seat], g.seats[seat+1:]...)
+		// The button stays with the player before the removed seat so that it passes
+		// to the player after the removed seat next round
+		if seat <= g.button {
+			g.button--
+		}
+		if seat < g.turn {
+			g.turn--
+		}
+	}
+	delete(g.players, id)
+	g.offerSeats()
+}
+
+// Remove away players whose grace period has run out (only between rounds) and pass on the seats
+// that waiting players did not take in time
+func (g *Game) removeExpired() {
+	g.offerSeats()
+	if g.handInProgress() {
+		return
+	}
+	for _, id := range append([]uint64{}, g.seats...) {
+		p := g.players[id]
+		if p.Status&PSTATUS_AWAY > 0 && g.now().Sub(p.AwaySince) >= g.awayGrace {
+			g.roundLogger.Printf("Removed %s after being away for %v\n", *p.Name, g.awayGrace)
+			g.cashOut(p)
+			g.removePlayer(id)
+		} else if p.orbitsOut >= g.maxSitOut {
+			g.roundLogger.Printf("Removed %s after sitting out for %d orbits\n", *p.Name, p.orbitsOut)
+			g.cashOut(p)
+			g.removePlayer(id)
+		}
+	}
 }
 
 // Start a new round
 // Should only be possible in the river when there are no chips in the middle
 // and everyone has checked or called
 func (g *Game) NewRound() error {
-	// TODO
+	if !g.Playing() {
+		return fmt.Errorf("Cannot start a round while the game is paused")
+	}
+	if g.handInProgress() {
+		return fmt.Errorf("Cannot start a round while round %d is in progress", g.roundNum)
+	}
+	g.removeExpired()
+
+	canPlay := func(p *Player) bool { return p.Chips > 0 && p.Status&PSTATUS_LEAVING == 0 }
+	active := func(p *Player) bool {
+		return canPlay(p) && p.Status&(PSTATUS_SITTING_OUT|PSTATUS_WAIT_FOR_BB) == 0
+	}
+	waiting := func(p *Player) bool {
+		return canPlay(p) && p.Status&PSTATUS_SITTING_OUT == 0 && p.Status&PSTATUS_WAIT_FOR_BB > 0
+	}
+	numActive, numWaiting := 0, 0
+	for _, id := range g.seats {
+		p := g.players[id]
+		p.Hand = g.emptyHand()
+		p.up = nil
+		p.acted = false
+		if active(p) {
+			numActive++
+		} else if waiting(p) {
+			numWaiting++
+		}
+	}
+	if numActive+numWaiting < 2 {
+		return fmt.Errorf("Need at least two players with chips to start a round but have %d", numActive+numWaiting)
+	}
+	// With nobody to wait behind, waiting players are dealt in for free
+	if numActive < 2 {
+		for _, id := range g.seats {
+			if p := g.players[id]; waiting(p) {
+				p.Status &= ^PSTATUS_WAIT_FOR_BB
+				p.owesBig, p.owesSmall = false, false
+			}
+		}
+	}
+
+	straddle, bomb := g.takeForcedBets()
+
+	// The blinds only go to active players, except that the big blind also goes to
+	// whoever is waiting for it (unless there are no blinds because it is a bomb pot).
+	// Anyone sitting out who the blinds pass over owes them.
+	g.button = g.nextSeat(g.button, active)
+	small := g.nextSeat(g.button, active)
+	big := g.nextSeat(small, func(p *Player) bool { return active(p) || waiting(p) })
+	g.markMissedBlinds(g.button, small, big)
+	// Heads up the button posts the small blind
+	if big == g.button {
+		small, big = g.button, small
+	}
+	for i, id := range g.seats {
+		if p := g.players[id]; active(p) || (i == big && !bomb) {
+			p.Status |= PSTATUS_PLAYING
+			p.Status &= ^PSTATUS_WAIT_FOR_BB
+			p.orbitsOut = 0
+		}
+	}
+
+	g.roundNum++
+	g.bettingRound = BROUND_PREFLOP
+	g.middle = [5]Card{NoCards, NoCards, NoCards, NoCards, NoCards}
+	g.pots = nil
+	g.newDeck()
+	inRound := func(p *Player) bool { return p.Status&PSTATUS_PLAYING > 0 }
+
+	for k := 0; k < len(g.emptyHand()); k++ {
+		for seat := g.nextSeat(g.button, inRound); ; seat = g.nextSeat(seat, inRound) {
+			card, err := g.dealCard()
+			if err != nil {
+				return err
+			}
+			g.seated(seat).Hand[k] = card
+			if seat == g.button {
+				break
+			}
+		}
+	}
+
+	g.dealtAt = g.now()
+
+	if bomb {
+		return g.postBombPot()
+	}
+	g.postBlinds(small, big)
+	g.currentBet = g.stakes
+	g.minRaise = g.stakes
+	// In fixed-limit games the big blind counts as the first bet
+	g.raises = 1
+	g.roundLogger.Printf("Round %d: %s has the button, %s posts %d and %s posts %d\n", g.roundNum,
+		*g.seated(g.button).Name, *g.seated(small).Name, g.seated(small).Bet, *g.seated(big).Name, g.seated(big).Bet)
+
+	g.turn = big
+	if seat := g.straddleSeat(straddle, small, big); seat >= 0 {
+		g.postStraddle(seat)
+		g.turn = seat
+	}
+	g.postAntes(big)
+	g.pots = g.buildPots()
+	g.advanceTurn()
 	return nil
-}
\ No newline at end of file
+}
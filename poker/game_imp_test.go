@@ -1,15 +1,107 @@
 package poker
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
+// newPlayingGame creates a public game with the creator and players p1, ..., p<n-1>
+// seated in that order, and starts playing (without starting a round)
+func newPlayingGame(t *testing.T, n int, args *GameInitArgs) *Game {
+	return newPlayingTable(t, n, args).(*Game)
+}
+
+// newPlayingTable is newPlayingGame for any kind of game (i.e. stud or draw)
+func newPlayingTable(t *testing.T, n int, args *GameInitArgs) GameLike {
+	args.Name = pointer(game_name)
+	args.Public = true
+	_, g, err := New(pointer(creator), args)
+	if err != nil {
+		t.Fatalf("Error initializing game: `%v`\n", err)
+	}
+	for i := 1; i < n; i++ {
+		if _, added, err := g.AddPlayer(pointer(fmt.Sprintf("p%d", i)), nil); !added || err != nil {
+			t.Fatalf("Failed to add (added = %v) player p%d: `%v`", added, i, err)
+		}
+	}
+	if playing, err := g.Play(pointer(creator)); !playing || err != nil {
+		t.Fatalf("Failed to play (playing = %v): `%v`", playing, err)
+	}
+	return g
+}
+
+// the name of the player whose turn it is
+func turnName(g *Game) string {
+	if g.turn < 0 {
+		return ""
+	}
+	return *g.seated(g.turn).Name
+}
+
+func mustMove(t *testing.T, g *Game, move uint64, chips uint64, mover string) {
+	if moved, err := g.Move(move, chips, pointer(mover)); !moved || err != nil {
+		t.Fatalf("%s failed to make move %d (moved = %v) on %s's turn: `%v`", mover, move, moved, turnName(g), err)
+	}
+}
+
+func totalChips(g *Game) uint64 {
+	var total uint64 = 0
+	for _, p := range g.players {
+		total += p.Chips + p.Bet + p.Pot
+	}
+	return total
+}
+
 func TestTeardownRegularGame(t *testing.T) {
 	// TODO
 }
 
 func TestFullRoundOrderOkAndValuesOk(t *testing.T) {
-	// TODO
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100})
+	defer g.Teardown()
+	before := totalChips(g)
+
+	if err := g.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	// creator has the button, p1 the small blind and p2 the big blind
+	if g.button != 0 || turnName(g) != creator {
+		t.Fatalf("Expected %s to have the button and act first but %s acts first", creator, turnName(g))
+	}
+	if moved, _ := g.Move(MTYPE_CALL, 0, pointer("p1")); moved {
+		t.Fatalf("p1 moved out of turn")
+	}
+	mustMove(t, g, MTYPE_CALL, 0, creator)
+	mustMove(t, g, MTYPE_CALL, 0, "p1")
+	if incremented, _ := g.Increment(); incremented {
+		t.Fatalf("Incremented before the big blind had the option")
+	}
+	mustMove(t, g, MTYPE_CHECK, 0, "p2")
+
+	for _, bround := range []uint64{BROUND_FLOP, BROUND_TURN, BROUND_RIVER} {
+		if incremented, err := g.Increment(); !incremented || err != nil {
+			t.Fatalf("Failed to increment (incremented = %v) to %d: `%v`", incremented, bround, err)
+		}
+		if g.bettingRound != bround || turnName(g) != "p1" {
+			t.Fatalf("Expected betting round %d with p1 to act but got %d with %s", bround, g.bettingRound, turnName(g))
+		}
+		mustMove(t, g, MTYPE_CHECK, 0, "p1")
+		mustMove(t, g, MTYPE_CHECK|MTYPE_FOLD, 0, "p2")
+		mustMove(t, g, MTYPE_CHECK, 0, creator)
+	}
+	if pots := g.Pots(); len(pots) != 1 || pots[0] != 300 {
+		t.Fatalf("Expected a single pot of 300 but got %v", pots)
+	}
+	if msg, err := g.Resolve(); msg == nil || err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+	if after := totalChips(g); after != before {
+		t.Fatalf("Started with %d chips but ended with %d", before, after)
+	}
 }
 
 func TestFullRoundTwoPotsTwoWinnersOnePerPot(t *testing.T) {
@@ -27,3 +119,719 @@ func TestAdminRequestUpdates(t *testing.T) {
 func TestCardsLeftRightAndBothAndBothSeperately(t *testing.T) {
 	// TODO
 }
+
+func TestDisconnectedPlayerChecksAndFolds(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100})
+	defer g.Teardown()
+	if err := g.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+
+	// p2 has the big blind so they check when everyone calls
+	if away, err := g.Disconnect(pointer("p2")); !away || err != nil {
+		t.Fatalf("Failed to disconnect (away = %v): `%v`", away, err)
+	}
+	mustMove(t, g, MTYPE_CALL, 0, creator)
+	mustMove(t, g, MTYPE_CALL, 0, "p1")
+	if p, _ := g.getPlayer(pointer("p2")); !inHand(p) || !g.bettingDone() {
+		t.Fatalf("Away big blind should have checked (in hand = %v, betting done = %v)", inHand(p), g.bettingDone())
+	}
+
+	// but they fold to a bet
+	g.Increment()
+	mustMove(t, g, MTYPE_BET, 100, "p1")
+	if p, _ := g.getPlayer(pointer("p2")); inHand(p) || turnName(g) != creator {
+		t.Fatalf("Away player should have folded to a bet (in hand = %v, turn = %s)", inHand(p), turnName(g))
+	}
+}
+
+func TestReconnectKeepsSeat(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{})
+	defer g.Teardown()
+
+	g.Disconnect(pointer("p1"))
+	if players := g.Players(); !players[1].Away {
+		t.Fatalf("p1 should be away")
+	}
+	if back, err := g.Reconnect(pointer("p1")); !back || err != nil {
+		t.Fatalf("Failed to reconnect (back = %v): `%v`", back, err)
+	}
+	if back, _ := g.Reconnect(pointer("p1")); back {
+		t.Fatalf("Reconnected a player who was not away")
+	}
+	players := g.Players()
+	if players[1].Name != "p1" || players[1].Away || players[1].Chips != g.startingChips {
+		t.Fatalf("Expected p1 in seat one with %d chips but got %+v", g.startingChips, players[1])
+	}
+}
+
+func TestModKeepsAwayStatus(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{})
+	defer g.Teardown()
+
+	// modding only touches permissions, so an away player stays away (and keeps their seat)
+	g.Disconnect(pointer("p1"))
+	if modded, err := g.ModPlayer(pointer(creator), pointer("p1"), PPERM_ADMIN); !modded || err != nil {
+		t.Fatalf("Failed to mod (modded = %v): `%v`", modded, err)
+	}
+	if admin, _ := g.isAdmin(pointer("p1")); !admin || !g.Players()[1].Away {
+		t.Fatalf("Expected p1 to be an admin who is still away (admin = %v)", admin)
+	}
+	if modded, err := g.ModPlayer(pointer(creator), pointer("p1"), 0); !modded || err != nil {
+		t.Fatalf("Failed to unmod (modded = %v): `%v`", modded, err)
+	}
+	if admin, _ := g.isAdmin(pointer("p1")); admin || !g.Players()[1].Away {
+		t.Fatalf("Expected p1 to lose admin and still be away (admin = %v)", admin)
+	}
+}
+
+func TestAwayPlayerRemovedAfterGrace(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{AwayGrace: time.Minute})
+	defer g.Teardown()
+	now := time.Now()
+	g.now = func() time.Time { return now }
+
+	g.Disconnect(pointer("p1"))
+	now = now.Add(30 * time.Second)
+	if err := g.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	if len(g.Players()) != 3 {
+		t.Fatalf("Away player was removed before the grace period ran out")
+	}
+
+	// the player is not removed in the middle of a round
+	now = now.Add(time.Minute)
+	g.Move(MTYPE_FOLD, 0, pointer(turnName(g)))
+	if len(g.Players()) != 3 {
+		t.Fatalf("Away player was removed in the middle of a round")
+	}
+	if back, err := g.Reconnect(pointer("p1")); back || err == nil {
+		t.Fatalf("Expected p1 not to get their seat back after the grace period (back = %v)", back)
+	}
+	if _, err := g.Resolve(); err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+	if _, found := g.getPlayer(pointer("p1")); found || len(g.seats) != 2 {
+		t.Fatalf("Away player should have been removed after the grace period")
+	}
+}
+
+func TestKickMidRoundForfeitsBet(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100, StartingChips: 1000})
+	defer g.Teardown()
+	if err := g.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+
+	// creator raises and is kicked by the new admin while p1 is thinking
+	g.ModPlayer(pointer(creator), pointer("p1"), PPERM_ADMIN)
+	mustMove(t, g, MTYPE_BET, 300, creator)
+	if kicked, err := g.KickPlayer(pointer("p1"), pointer(creator)); !kicked || err != nil {
+		t.Fatalf("Failed to kick (kicked = %v): `%v`", kicked, err)
+	}
+	if len(g.seats) != 3 || turnName(g) != "p1" {
+		t.Fatalf("Kicked player should keep their seat until the end of the round (seats = %d, turn = %s)", len(g.seats), turnName(g))
+	}
+	cashOuts := g.CashOuts()
+	if len(cashOuts) != 1 || cashOuts[0].Name != creator || cashOuts[0].Chips != 700 {
+		t.Fatalf("Expected %s to cash out 700 chips but got %+v", creator, cashOuts)
+	}
+
+	// a player who is already on their way out is not kicked (or cashed out) twice
+	if kicked, err := g.KickPlayer(pointer("p1"), pointer(creator)); kicked || err != nil {
+		t.Fatalf("Expected kicking %s again to do nothing (kicked = %v): `%v`", creator, kicked, err)
+	}
+	if len(g.CashOuts()) != 1 {
+		t.Fatalf("Expected %s to cash out once but got %+v", creator, g.CashOuts())
+	}
+
+	// the raise was never called but it stays in the pot
+	mustMove(t, g, MTYPE_FOLD, 0, "p1")
+	msg, err := g.Resolve()
+	if err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+	if *msg != "p2 wins 450 chips from pot 0" {
+		t.Fatalf("Expected p2 to win the forfeited raise but got `%s`", *msg)
+	}
+	if _, found := g.getPlayer(pointer(creator)); found || len(g.seats) != 2 {
+		t.Fatalf("Kicked player should have been removed at the end of the round")
+	}
+
+	// p1 keeps the button moving as if creator was still there
+	if err := g.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	if *g.seated(g.button).Name != "p1" {
+		t.Fatalf("Expected p1 to have the button but %s has it", *g.seated(g.button).Name)
+	}
+}
+
+func TestLeaveOnTurnAndBetweenRounds(t *testing.T) {
+	g := newPlayingGame(t, 4, &GameInitArgs{Stakes: 100, StartingChips: 1000})
+	defer g.Teardown()
+	if err := g.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+
+	// p3 is first to act and leaves, so the turn passes on to creator
+	if left, err := g.LeavePlayer(pointer("p3")); !left || err != nil {
+		t.Fatalf("Failed to leave (left = %v): `%v`", left, err)
+	}
+	if turnName(g) != creator {
+		t.Fatalf("Expected %s to act after p3 left but it is %s's turn", creator, turnName(g))
+	}
+	if left, _ := g.LeavePlayer(pointer("p3")); left {
+		t.Fatalf("Left twice")
+	}
+	mustMove(t, g, MTYPE_CALL, 0, creator)
+	mustMove(t, g, MTYPE_CALL, 0, "p1")
+	mustMove(t, g, MTYPE_CHECK, 0, "p2")
+	for incremented, _ := g.Increment(); incremented; incremented, _ = g.Increment() {
+		for turnName(g) != "" {
+			mustMove(t, g, MTYPE_CHECK, 0, turnName(g))
+		}
+	}
+	if _, err := g.Resolve(); err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+
+	// between rounds players leave right away
+	g.LeavePlayer(pointer("p2"))
+	players := g.Players()
+	if len(players) != 2 || players[0].Name != creator || players[1].Name != "p1" {
+		t.Fatalf("Expected %s and p1 to be left but got %+v", creator, players)
+	}
+	if cashOuts := g.CashOuts(); len(cashOuts) != 2 || cashOuts[0].Chips != 1000 {
+		t.Fatalf("Expected two cash outs with p3 cashing out 1000 but got %+v", cashOuts)
+	}
+}
+
+func TestFullTableWaitlistOffersSeatsInOrder(t *testing.T) {
+	g := newPlayingGame(t, 2, &GameInitArgs{MaxPlayers: 2, SeatOfferWindow: time.Minute})
+	defer g.Teardown()
+	now := time.Now()
+	g.now = func() time.Time { return now }
+
+	for _, name := range []string{"w1", "w2"} {
+		waiting, joined, err := g.AddPlayer(pointer(name), nil)
+		if joined || err != nil || waiting == nil || *waiting != name {
+			t.Fatalf("Expected %s to wait for a seat (joined = %v): `%v`", name, joined, err)
+		}
+	}
+	if len(g.Players()) != 2 {
+		t.Fatalf("Table should be capped at two players but has %d", len(g.Players()))
+	}
+
+	// a seat frees up and is offered to w1 only
+	g.LeavePlayer(pointer("p1"))
+	waitlist := g.Waitlist()
+	if len(waitlist) != 2 || !waitlist[0].Offered || waitlist[1].Offered {
+		t.Fatalf("Expected only w1 to be offered a seat but got %+v", waitlist)
+	}
+	if seated, _ := g.AcceptSeat(pointer("w2")); seated {
+		t.Fatalf("w2 took a seat that was offered to w1")
+	}
+	// newcomers wait behind everyone else even if a seat is free
+	if _, joined, _ := g.AddPlayer(pointer("w3"), nil); joined {
+		t.Fatalf("w3 jumped the queue")
+	}
+
+	// w1 does not take it in time so it passes to w2
+	now = now.Add(2 * time.Minute)
+	waitlist = g.Waitlist()
+	if len(waitlist) != 2 || waitlist[0].Name != "w2" || !waitlist[0].Offered {
+		t.Fatalf("Expected the seat to pass to w2 but got %+v", waitlist)
+	}
+	if seated, err := g.AcceptSeat(pointer("w2")); !seated || err != nil {
+		t.Fatalf("Failed to seat w2 (seated = %v): `%v`", seated, err)
+	}
+	players := g.Players()
+	if len(players) != 2 || players[1].Name != "w2" {
+		t.Fatalf("Expected w2 to sit in the free seat but got %+v", players)
+	}
+	if waitlist := g.Waitlist(); len(waitlist) != 1 || waitlist[0].Offered {
+		t.Fatalf("Expected w3 to keep waiting but got %+v", waitlist)
+	}
+}
+
+func TestSeatOffersExpireBetweenRounds(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{MaxPlayers: 3, SeatOfferWindow: time.Minute})
+	defer g.Teardown()
+	now := time.Now()
+	g.now = func() time.Time { return now }
+	g.AddPlayer(pointer("w1"), nil)
+	g.AddPlayer(pointer("w2"), nil)
+	g.LeavePlayer(pointer("p2"))
+	if !g.waitlist[0].offered.Equal(now) || !g.waitlist[1].offered.IsZero() {
+		t.Fatalf("Expected only w1 to be offered a seat")
+	}
+
+	// nobody asks about the waitlist but starting the next round still passes the seat on
+	now = now.Add(2 * time.Minute)
+	mustNewRound(t, g)
+	if len(g.waitlist) != 1 || *g.waitlist[0].name != "w2" || !g.waitlist[0].offered.Equal(now) {
+		t.Fatalf("Expected the seat to pass to w2 when the round starts")
+	}
+}
+
+func TestSpectatorsDoNotTakeSeatsAndWaitOnTheRail(t *testing.T) {
+	g := newPlayingGame(t, 2, &GameInitArgs{MaxPlayers: 2})
+	defer g.Teardown()
+
+	if _, joined, err := g.AddSpectator(pointer("rail"), nil); !joined || err != nil {
+		t.Fatalf("Failed to add spectator (joined = %v): `%v`", joined, err)
+	}
+	if _, joined, err := g.AddSpectator(pointer("p1"), nil); joined || err == nil {
+		t.Fatalf("Seated player joined the rail")
+	}
+	if len(g.Players()) != 2 || len(g.Spectators()) != 1 {
+		t.Fatalf("Expected two players and one spectator but got %d and %d", len(g.Players()), len(g.Spectators()))
+	}
+
+	// the spectator waits for a seat from the rail and leaves it once seated
+	if _, joined, _ := g.AddPlayer(pointer("rail"), nil); joined {
+		t.Fatalf("Spectator took a seat at a full table")
+	}
+	g.LeavePlayer(pointer("p1"))
+	if seated, err := g.AcceptSeat(pointer("rail")); !seated || err != nil {
+		t.Fatalf("Failed to seat spectator (seated = %v): `%v`", seated, err)
+	}
+	if len(g.Spectators()) != 0 {
+		t.Fatalf("Seated spectator is still on the rail")
+	}
+}
+
+func TestSpectatorsSeeFinishedHandsAfterTVDelay(t *testing.T) {
+	g := newPlayingGame(t, 2, &GameInitArgs{TVDelay: time.Minute})
+	defer g.Teardown()
+	now := time.Now()
+	g.now = func() time.Time { return now }
+	g.AddSpectator(pointer("rail"), nil)
+	if err := g.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+
+	for _, p := range g.SpectatorPlayers() {
+		if p.Cards[0].String() != "" || p.Cards[1].String() != "" {
+			t.Fatalf("Spectator saw %s's cards %s %s before the delay", p.Name, p.Cards[0], p.Cards[1])
+		}
+	}
+	now = now.Add(time.Minute)
+	for _, p := range g.SpectatorPlayers() {
+		if p.Cards[0].String() != "" || p.Cards[1].String() != "" {
+			t.Fatalf("Spectator saw %s's cards %s %s while the hand is in progress", p.Name, p.Cards[0], p.Cards[1])
+		}
+	}
+	mustMove(t, g, MTYPE_FOLD, 0, turnName(g))
+	if _, err := g.Resolve(); err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+	for _, p := range g.SpectatorPlayers() {
+		if p.Cards[0].String() == "" || p.Cards[1].String() == "" {
+			t.Fatalf("Spectator did not see %s's cards after the hand and the delay", p.Name)
+		}
+	}
+}
+
+func TestRailChatIsOnlyForSpectators(t *testing.T) {
+	g := newPlayingGame(t, 2, &GameInitArgs{})
+	defer g.Teardown()
+	g.AddSpectator(pointer("rail"), nil)
+
+	if sent, err := g.RailMessage(pointer("rail"), pointer("nice hand")); !sent || err != nil {
+		t.Fatalf("Failed to send rail message (sent = %v): `%v`", sent, err)
+	}
+	if sent, _ := g.RailMessage(pointer("p1"), pointer("I can hear you")); sent {
+		t.Fatalf("Player sent a message to the rail")
+	}
+	for i := 0; i < MAX_RAIL_MESSAGES; i++ {
+		g.RailMessage(pointer("rail"), pointer(fmt.Sprintf("%d", i)))
+	}
+	chat := g.RailChat()
+	if len(chat) != MAX_RAIL_MESSAGES || chat[0] != "rail: 0" {
+		t.Fatalf("Expected the %d most recent messages starting with `rail: 0` but got %d starting with `%s`", MAX_RAIL_MESSAGES, len(chat), chat[0])
+	}
+}
+
+// play out the rest of a round by folding whoever has to act and resolve it
+func foldRound(t *testing.T, g *Game) {
+	for incremented := true; incremented; incremented, _ = g.Increment() {
+		for turnName(g) != "" {
+			mustMove(t, g, MTYPE_FOLD, 0, turnName(g))
+		}
+	}
+	if _, err := g.Resolve(); err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+}
+
+func mustNewRound(t *testing.T, g *Game) {
+	if err := g.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+}
+
+func TestSittingOutOwesMissedBlinds(t *testing.T) {
+	g := newPlayingGame(t, 4, &GameInitArgs{Stakes: 100})
+	defer g.Teardown()
+
+	// p3 sits out from the next round (and can do so while it is not their turn)
+	mustNewRound(t, g)
+	if moved, err := g.Move(MTYPE_SITOUT_NEXT_ROUND, 0, pointer("p2")); !moved || err != nil {
+		t.Fatalf("Failed to sit out (moved = %v): `%v`", moved, err)
+	}
+	foldRound(t, g)
+
+	// the small blind skips p2 this round and the big blind skips them two rounds later
+	mustNewRound(t, g)
+	p2, _ := g.getPlayer(pointer("p2"))
+	if p2.Status&PSTATUS_PLAYING > 0 || p2.owesBig || !p2.owesSmall {
+		t.Fatalf("Expected p2 to sit out and owe only the small blind (owes big = %v, owes small = %v)", p2.owesBig, p2.owesSmall)
+	}
+	foldRound(t, g)
+	mustNewRound(t, g)
+	foldRound(t, g)
+	mustNewRound(t, g)
+	if !p2.owesBig || !p2.owesSmall || p2.orbitsOut != 1 {
+		t.Fatalf("Expected p2 to owe both blinds after an orbit (owes big = %v, owes small = %v, orbits = %d)", p2.owesBig, p2.owesSmall, p2.orbitsOut)
+	}
+	foldRound(t, g)
+	mustNewRound(t, g)
+	foldRound(t, g)
+
+	// posting right away puts the big blind in live and the small blind in dead
+	if in, err := g.SitIn(pointer("p2"), true); !in || err != nil {
+		t.Fatalf("Failed to sit in (in = %v): `%v`", in, err)
+	}
+	mustNewRound(t, g)
+	if p2.Status&PSTATUS_PLAYING == 0 || p2.Bet != 100 || p2.Pot != 50 || p2.owesBig || p2.owesSmall {
+		t.Fatalf("Expected p2 to post 100 live and 50 dead but posted %d live and %d dead", p2.Bet, p2.Pot)
+	}
+}
+
+func TestNewPlayerWaitsForBigBlind(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100})
+	defer g.Teardown()
+	mustNewRound(t, g)
+	foldRound(t, g)
+	mustNewRound(t, g)
+	foldRound(t, g)
+
+	// the blinds are about to pass p3 by, so p3 waits two rounds before being dealt in
+	g.AddPlayer(pointer("p3"), nil)
+	p3, _ := g.getPlayer(pointer("p3"))
+	for round := 0; round < 2; round++ {
+		mustNewRound(t, g)
+		if p3.Status&PSTATUS_PLAYING > 0 {
+			t.Fatalf("p3 was dealt in before the big blind reached them")
+		}
+		foldRound(t, g)
+	}
+	mustNewRound(t, g)
+	if p3.Status&PSTATUS_PLAYING == 0 || p3.Bet != 100 || p3.owesBig {
+		t.Fatalf("Expected p3 to be dealt in as the big blind (bet = %d)", p3.Bet)
+	}
+	foldRound(t, g)
+
+	// whereas p4 posts right away
+	g.AddPlayer(pointer("p4"), nil)
+	g.SitIn(pointer("p4"), true)
+	mustNewRound(t, g)
+	if p4, _ := g.getPlayer(pointer("p4")); p4.Status&PSTATUS_PLAYING == 0 || p4.Bet != 100 {
+		t.Fatalf("Expected p4 to post the big blind and be dealt in (bet = %d)", p4.Bet)
+	}
+}
+
+func TestSittingOutTooLongLosesSeat(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{MaxSitOutOrbits: 1})
+	defer g.Teardown()
+
+	g.Move(MTYPE_SITOUT_NEXT_ROUND, 0, pointer("p1"))
+	for round := 0; round < 3 && len(g.seats) == 3; round++ {
+		mustNewRound(t, g)
+		foldRound(t, g)
+	}
+	if _, found := g.getPlayer(pointer("p1")); found {
+		t.Fatalf("p1 kept their seat after sitting out for an orbit")
+	}
+	if cashOuts := g.CashOuts(); len(cashOuts) != 1 || cashOuts[0].Name != "p1" || cashOuts[0].Chips != g.startingChips {
+		t.Fatalf("Expected p1 to cash out their whole stack but got %+v", cashOuts)
+	}
+}
+
+// expect the players to be asked to act in order, calling (or checking) each time
+func callAround(t *testing.T, g *Game, order ...string) {
+	for _, name := range order {
+		if turnName(g) != name {
+			t.Fatalf("Expected %s to act but it was %s's turn", name, turnName(g))
+		}
+		mustMove(t, g, MTYPE_CHECK|MTYPE_CALL, 0, name)
+	}
+	if turnName(g) != "" {
+		t.Fatalf("Expected the betting round to be over but it is %s's turn", turnName(g))
+	}
+}
+
+func TestStraddlersActLastBeforeTheFlop(t *testing.T) {
+	g := newPlayingGame(t, 4, &GameInitArgs{Stakes: 100, Straddle: STRADDLE_UTG})
+	defer g.Teardown()
+
+	// the creator has the button, p1 and p2 post the blinds and p3 straddles under the gun
+	mustNewRound(t, g)
+	p3, _ := g.getPlayer(pointer("p3"))
+	if p3.Bet != 200 || g.currentBet != 200 {
+		t.Fatalf("Expected p3 to straddle 200 but they bet %d (current bet is %d)", p3.Bet, g.currentBet)
+	}
+	callAround(t, g, creator, "p1", "p2", "p3")
+	foldRound(t, g)
+
+	// an admin can have the button straddle a single hand; the blinds then act first
+	if straddled, err := g.Straddle(pointer("p1"), STRADDLE_BUTTON); straddled || err != nil {
+		t.Fatalf("Expected a non-admin not to be able to call a straddle (straddled = %v): `%v`", straddled, err)
+	}
+	if straddled, err := g.Straddle(pointer(creator), STRADDLE_BUTTON); !straddled || err != nil {
+		t.Fatalf("Failed to call a button straddle (straddled = %v): `%v`", straddled, err)
+	}
+	mustNewRound(t, g)
+	callAround(t, g, "p2", "p3", creator, "p1")
+	foldRound(t, g)
+
+	// and the table goes back to straddling under the gun
+	mustNewRound(t, g)
+	callAround(t, g, "p2", "p3", creator, "p1")
+}
+
+func TestAntesAreDeadMoney(t *testing.T) {
+	g := newPlayingGame(t, 4, &GameInitArgs{Stakes: 100, AnteStructure: ANTE_BIG_BLIND})
+	defer g.Teardown()
+	total := totalChips(g)
+
+	// the big blind antes for the whole table but only has to be called for the blind
+	mustNewRound(t, g)
+	p2, _ := g.getPlayer(pointer("p2"))
+	if p2.Bet != 100 || p2.Pot != 100 || len(g.Pots()) != 1 || g.Pots()[0] != 100 {
+		t.Fatalf("Expected p2 to post 100 live and 100 dead but posted %d live and %d dead (pots %v)", p2.Bet, p2.Pot, g.Pots())
+	}
+	mustMove(t, g, MTYPE_FOLD, 0, "p3")
+	mustMove(t, g, MTYPE_FOLD, 0, creator)
+	mustMove(t, g, MTYPE_FOLD, 0, "p1")
+	foldRound(t, g)
+	if p2.Chips != 1050 || totalChips(g) != total {
+		t.Fatalf("Expected p2 to win their ante back and the small blind (1050 chips) but has %d", p2.Chips)
+	}
+
+	g = newPlayingGame(t, 3, &GameInitArgs{Stakes: 100, AnteStructure: ANTE_EVERYONE, Ante: 10})
+	defer g.Teardown()
+	mustNewRound(t, g)
+	callAround(t, g, creator, "p1", "p2")
+	if pots := g.Pots(); len(pots) != 1 || pots[0] != 30 {
+		t.Fatalf("Expected a pot of 30 chips of antes before the bets are collected but got %v", pots)
+	}
+	if incremented, err := g.Increment(); !incremented || err != nil {
+		t.Fatalf("Failed to deal the flop (incremented = %v): `%v`", incremented, err)
+	}
+	if pots := g.Pots(); len(pots) != 1 || pots[0] != 330 {
+		t.Fatalf("Expected a pot of 330 chips on the flop but got %v", pots)
+	}
+}
+
+func TestBombPotsDealStraightToTheFlop(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100, BombPotAnte: 200, BombPotEvery: 3})
+	defer g.Teardown()
+
+	isBombPot := func() bool {
+		for _, id := range g.seats {
+			if p := g.players[id]; p.Bet != 0 || p.Pot != 200 {
+				return false
+			}
+		}
+		return g.bettingRound == BROUND_FLOP && g.middle[2] != NoCards && g.middle[3] == NoCards
+	}
+
+	// every third hand is a bomb pot and admins can call one for the next hand
+	if bombed, err := g.BombPot(pointer(creator)); !bombed || err != nil {
+		t.Fatalf("Failed to call a bomb pot (bombed = %v): `%v`", bombed, err)
+	}
+	for round, bomb := range []bool{true, false, true, false} {
+		mustNewRound(t, g)
+		if isBombPot() != bomb {
+			t.Fatalf("Expected round %d to be a bomb pot: %v", round+1, bomb)
+		}
+		if bomb {
+			// the first player left of the button acts first
+			callAround(t, g, *g.seated((g.button + 1) % 3).Name, *g.seated((g.button + 2) % 3).Name, *g.seated(g.button).Name)
+			if pots := g.Pots(); len(pots) != 1 || pots[0] != 600 {
+				t.Fatalf("Expected a pot of 600 chips but got %v", pots)
+			}
+		}
+		foldRound(t, g)
+	}
+}
+
+func TestRunItTwiceSplitsThePot(t *testing.T) {
+	g := newPlayingGame(t, 2, &GameInitArgs{Stakes: 100})
+	defer g.Teardown()
+	total := totalChips(g)
+
+	// heads up the creator has the button and shoves; nobody can run it more than once until p1 calls
+	mustNewRound(t, g)
+	mustMove(t, g, MTYPE_BET, 950, creator)
+	if offer := g.RunItOffer(); len(offer) != 0 {
+		t.Fatalf("Expected no offer to run it before the all in is called but got %v", offer)
+	}
+	mustMove(t, g, MTYPE_CALL, 0, "p1")
+	if offer := g.RunItOffer(); len(offer) != 2 || offer[0] != creator || offer[1] != "p1" {
+		t.Fatalf("Expected both players to be asked to run it but got %v", offer)
+	}
+	if agreed, err := g.AgreeRunIt(pointer(creator), 2); !agreed || err != nil {
+		t.Fatalf("Failed to agree to run it twice (agreed = %v): `%v`", agreed, err)
+	}
+	if agreed, err := g.AgreeRunIt(pointer("p1"), 20); agreed || err == nil {
+		t.Fatalf("Expected to be unable to run it 20 times (agreed = %v)", agreed)
+	}
+
+	// the board is run as many times as the most cautious player agreed to and all at once
+	if agreed, err := g.AgreeRunIt(pointer("p1"), 3); !agreed || err != nil {
+		t.Fatalf("Failed to agree to run it three times (agreed = %v): `%v`", agreed, err)
+	}
+	if incremented, err := g.Increment(); incremented || err != nil {
+		t.Fatalf("Expected the flop not to be dealt on its own (incremented = %v): `%v`", incremented, err)
+	}
+	msg, err := g.Resolve()
+	if err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+	if !strings.Contains(*msg, "Run 1: ") || !strings.Contains(*msg, "Run 2: ") || strings.Contains(*msg, "Run 3: ") {
+		t.Fatalf("Expected both boards in the message but got `%s`", *msg)
+	}
+	// no card shows up on both boards
+	if left := g.deck.Count(); left != 52-4-10 {
+		t.Fatalf("Expected two runouts of five cards to leave %d cards but %d are left", 52-4-10, left)
+	}
+	// each runout pays out half of the pot
+	for run := 1; run <= 2; run++ {
+		var paid uint64 = 0
+		for _, line := range strings.Split(*msg, "\n") {
+			var name string
+			var won, pot uint64
+			var r int
+			if n, _ := fmt.Sscanf(line, "%s wins %d chips from pot %d on run %d", &name, &won, &pot, &r); n == 4 && r == run {
+				paid += won
+			}
+		}
+		if paid != 1000 {
+			t.Fatalf("Expected run %d to pay out 1000 chips but it paid %d: `%s`", run, paid, *msg)
+		}
+	}
+	if totalChips(g) != total {
+		t.Fatalf("Expected %d chips in play but there are %d", total, totalChips(g))
+	}
+}
+
+func TestPotLimitOmaha(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100, Mode: GMODE_POT_LIMIT_OMAHA})
+	defer g.Teardown()
+
+	info, err := ioutil.ReadFile(filepath.Join(*g.gameDir, gameInitName))
+	if err != nil || !strings.Contains(string(info), `"Mode": "CONST_STAKES POT_LIMIT_OMAHA"`) {
+		t.Fatalf("Expected the game mode in %s but got `%s`: `%v`", gameInitName, info, err)
+	}
+
+	// everyone gets four different cards
+	mustNewRound(t, g)
+	var dealt CardSet = NoCards
+	for _, p := range g.Players() {
+		if len(p.Cards) != 4 {
+			t.Fatalf("Expected %s to have four cards but they have %d", p.Name, len(p.Cards))
+		}
+		for _, c := range p.Cards {
+			dealt |= CardSet(c.(Card))
+		}
+	}
+	if dealt.Count() != 12 {
+		t.Fatalf("Expected twelve different cards to be dealt but got %s", CardSetToString(dealt))
+	}
+
+	// the creator can call the big blind and raise the 250 chips that would then be in the pot
+	if moved, err := g.Move(MTYPE_BET, 351, pointer(creator)); moved || err == nil {
+		t.Fatalf("Expected a bet over the pot to fail (moved = %v)", moved)
+	}
+	mustMove(t, g, MTYPE_BET, 350, creator)
+	if g.currentBet != 350 {
+		t.Fatalf("Expected the creator to bet to 350 but the bet is %d", g.currentBet)
+	}
+}
+
+func TestShortDeckDealsFromThirtySixCards(t *testing.T) {
+	if _, _, err := New(pointer(creator), &GameInitArgs{Mode: GMODE_TRIPS_BEAT_STRAIGHTS}); err == nil {
+		t.Fatalf("Expected three of a kind beating straights to need short-deck")
+	}
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100, Mode: GMODE_SHORT_DECK | GMODE_TRIPS_BEAT_STRAIGHTS})
+	defer g.Teardown()
+
+	mustNewRound(t, g)
+	if left := g.deck.Count(); left != 36-6 {
+		t.Fatalf("Expected %d cards left in the deck but there are %d", 36-6, left)
+	}
+	for _, p := range g.Players() {
+		for _, c := range p.Cards {
+			if CardSet(c.(Card))&ShortDeck == 0 {
+				t.Fatalf("Dealt %s to %s from outside of the short deck", c, p.Name)
+			}
+		}
+	}
+
+	// three of a kind beats a straight at this table
+	p1, _ := g.getPlayer(pointer("p1"))
+	p2, _ := g.getPlayer(pointer("p2"))
+	board := Card(SixOfClubs | SixOfDiamonds | SevenOfHearts | EightOfSpades | KingOfHearts)
+	p1.Hand = []Card{Card(NineOfClubs), Card(TenOfDiamonds)}
+	p2.Hand = []Card{Card(SixOfHearts), Card(AceOfClubs)}
+	if g.handValue(p2, board) <= g.handValue(p1, board) {
+		t.Fatalf("Expected three sixes to beat a ten high straight")
+	}
+}
+
+func TestOmahaHiLoSplitsThePot(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100, Mode: GMODE_POT_LIMIT_OMAHA | GMODE_HI_LO})
+	defer g.Teardown()
+	if _, _, err := New(pointer(creator), &GameInitArgs{Mode: GMODE_SHORT_DECK | GMODE_HI_LO}); err == nil {
+		t.Fatalf("Expected short-deck hi/lo to be refused")
+	}
+
+	// everyone sees the river for the big blind
+	mustNewRound(t, g)
+	callAround(t, g, creator, "p1", "p2")
+	for street := 0; street < 3; street++ {
+		if incremented, err := g.Increment(); !incremented || err != nil {
+			t.Fatalf("Failed to deal street %d (incremented = %v): `%v`", street, incremented, err)
+		}
+		callAround(t, g, "p1", "p2", creator)
+	}
+
+	// p1 has the best high (a flush), p2 the best low and the creator nothing
+	g.middle = [5]Card{Card(TwoOfHearts), Card(FiveOfHearts), Card(SevenOfHearts), Card(KingOfSpades), Card(QueenOfClubs)}
+	setHand := func(name string, cards ...CardSet) {
+		p, _ := g.getPlayer(pointer(name))
+		for i, c := range cards {
+			p.Hand[i] = Card(c)
+		}
+	}
+	setHand(creator, NineOfClubs, NineOfDiamonds, TenOfClubs, JackOfDiamonds)
+	setHand("p1", AceOfHearts, KingOfHearts, KingOfClubs, JackOfClubs)
+	setHand("p2", AceOfClubs, ThreeOfDiamonds, TenOfSpades, JackOfSpades)
+	msg, err := g.Resolve()
+	if err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+	for name, chips := range map[string]uint64{creator: 900, "p1": 1050, "p2": 1050} {
+		if p, _ := g.getPlayer(pointer(name)); p.Chips != chips {
+			t.Fatalf("Expected %s to have %d chips but they have %d: `%s`", name, chips, p.Chips, *msg)
+		}
+	}
+	if !strings.Contains(*msg, "p2 wins 150 chips from pot 0 with Seven-Five low") || !strings.Contains(*msg, "p1 wins 150 chips from pot 0 with Flush, Ace high for high") {
+		t.Fatalf("Expected a message for each half but got `%s`", *msg)
+	}
+}
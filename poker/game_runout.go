@@ -0,0 +1,136 @@
+package poker
+
+import (
+	"fmt"
+)
+
+// When everyone left in a hand is all in (or all but one of them) before the river there is no more
+// betting, so the players can agree to run the rest of the board more than once. Every runout is
+// dealt from what is left of the deck (so no card shows up on two boards) and every pot is split
+// evenly between the runouts, each share going to the best hands on its own board. The board is run
+// as many times as the most cautious player agreed to, and only once unless everyone agreed.
+
+// Whether the players in the hand can agree to run the rest of the board more than once
+func (g *Game) runItPossible() bool {
+	if !g.handInProgress() || g.bettingRound == BROUND_RIVER || g.liveCount() <= 1 || !g.bettingDone() {
+		return false
+	}
+	actors := 0
+	for _, id := range g.seats {
+		if canAct(g.players[id]) {
+			actors++
+		}
+	}
+	return actors <= 1
+}
+
+func (g *Game) boardCardsLeft() int {
+	left := 0
+	for _, c := range g.middle {
+		if c == NoCards {
+			left++
+		}
+	}
+	return left
+}
+
+// Return the names of the players who have to agree to run the board more than once (in order
+// of play) or nothing if the board cannot be run more than once
+func (g *Game) RunItOffer() []string {
+	names := make([]string, 0, len(g.seats))
+	if !g.runItPossible() {
+		return names
+	}
+	for _, id := range g.seats {
+		if p := g.players[id]; inHand(p) {
+			names = append(names, *p.Name)
+		}
+	}
+	return names
+}
+
+// Agree to run the rest of the board some number of times (once declines running it more than once)
+func (g *Game) AgreeRunIt(name *string, times uint64) (bool, error) {
+	p, found := g.getPlayer(name)
+	if !found {
+		return false, fmt.Errorf("Could not find player %s to run it", *name)
+	}
+	if !g.runItPossible() || !inHand(p) {
+		return false, nil
+	}
+	if times == 0 {
+		return false, fmt.Errorf("Cannot run the board zero times")
+	}
+	if needed := uint64(g.boardCardsLeft()) * times; needed > uint64(g.deck.Count()) {
+		return false, fmt.Errorf("Not enough cards left to run the board %d times", times)
+	}
+	if g.runItVotes == nil {
+		g.runItVotes = make(map[uint64]uint64)
+	}
+	g.runItVotes[p.Id] = times
+	g.roundLogger.Printf("%s agrees to run it %d times\n", *p.Name, times)
+	return true, nil
+}
+
+// Return how many times the board is run: the fewest times anyone in the hand agreed to
+func (g *Game) runItTimes() uint64 {
+	if !g.runItPossible() {
+		return 1
+	}
+	var times uint64 = 0
+	for _, id := range g.seats {
+		p := g.players[id]
+		if !inHand(p) {
+			continue
+		}
+		agreed, voted := g.runItVotes[p.Id]
+		if !voted {
+			return 1
+		}
+		if times == 0 || agreed < times {
+			times = agreed
+		}
+	}
+	return times
+}
+
+// Deal the rest of the board a number of times and split every pot between the runouts
+// (odd chips go to the earlier runouts), returning a line for every board and payout.
+// The middle is left showing the first runout.
+func (g *Game) runItOut(times uint64) ([]string, error) {
+	boards := make([]Card, times)
+	lines := make([]string, 0, int(times)*(len(g.pots)+1))
+	first := g.middle
+	for r := range boards {
+		runout := g.middle
+		boards[r] = NoCards
+		for i, c := range runout {
+			if c == NoCards {
+				card, err := g.dealCard()
+				if err != nil {
+					return nil, err
+				}
+				runout[i] = card
+			}
+			boards[r] |= runout[i]
+		}
+		if r == 0 {
+			first = runout
+		}
+		g.roundLogger.Printf("Run %d: %s\n", r+1, boards[r].String())
+		lines = append(lines, fmt.Sprintf("Run %d: %s", r+1, boards[r].String()))
+	}
+	for i, pot := range g.pots {
+		share := pot.Chips / times
+		odd := pot.Chips % times
+		for r, board := range boards {
+			chips := share
+			if uint64(r) < odd {
+				chips++
+			}
+			lines = append(lines, g.awardPot(i, pot.Players, chips, board, r+1)...)
+		}
+	}
+	g.middle = first
+	return lines, nil
+}
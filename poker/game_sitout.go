@@ -0,0 +1,98 @@
+package poker
+
+import (
+	"fmt"
+)
+
+// Players can sit out (MTYPE_SITOUT_NEXT_ROUND) and keep their seat, but every blind that passes
+// them by while they are away from the table is owed. When they sit back in they either post what
+// they owe right away (the big blind live and the small blind dead) or wait for the big blind to
+// reach them. New players who join after the game has started owe a big blind the same way. Anyone
+// who sits out for too many orbits loses their seat.
+
+func (g *Game) sitOut(p *Player) {
+	if p.Status&PSTATUS_SITTING_OUT == 0 {
+		p.Status |= PSTATUS_SITTING_OUT
+		g.roundLogger.Printf("%s sits out\n", *p.Name)
+	}
+}
+
+func (g *Game) SitIn(name *string, post bool) (bool, error) {
+	p, found := g.getPlayer(name)
+	if !found {
+		return false, fmt.Errorf("Could not find player %s to sit in", *name)
+	}
+	if p.Status&(PSTATUS_SITTING_OUT|PSTATUS_WAIT_FOR_BB) == 0 {
+		return false, nil
+	}
+	p.Status &= ^(PSTATUS_SITTING_OUT | PSTATUS_WAIT_FOR_BB)
+	p.orbitsOut = 0
+	if (p.owesBig || p.owesSmall) && !post {
+		p.Status |= PSTATUS_WAIT_FOR_BB
+	}
+	g.roundLogger.Printf("%s sits in\n", *p.Name)
+	return true, nil
+}
+
+// Call f on every seat strictly between from and to (going around the table)
+func (g *Game) forSeatsBetween(from int, to int, f func(*Player)) {
+	for i := (from + 1) % len(g.seats); i != to; i = (i + 1) % len(g.seats) {
+		f(g.seated(i))
+	}
+}
+
+// Players sitting out between the button and the small blind missed the small blind,
+// and those between the small and big blinds missed the big blind (once an orbit)
+func (g *Game) markMissedBlinds(button int, small int, big int) {
+	sittingOut := func(p *Player) bool {
+		return p.Chips > 0 && p.Status&PSTATUS_SITTING_OUT > 0 && p.Status&PSTATUS_LEAVING == 0
+	}
+	g.forSeatsBetween(button, small, func(p *Player) {
+		if sittingOut(p) {
+			p.owesSmall = true
+		}
+	})
+	g.forSeatsBetween(small, big, func(p *Player) {
+		if sittingOut(p) {
+			p.owesBig = true
+			p.orbitsOut++
+		}
+	})
+}
+
+// Post the blinds and whatever is owed by players who came back without waiting for the big blind
+func (g *Game) postBlinds(small int, big int) {
+	for i, id := range g.seats {
+		p := g.players[id]
+		if p.Status&PSTATUS_PLAYING == 0 {
+			continue
+		}
+		switch {
+		case i == big:
+			g.putIn(p, g.stakes)
+		case i == small && !p.owesBig:
+			g.putIn(p, g.stakes/2)
+		case p.owesBig:
+			// The small blind is dead (it does not count towards calling) unless they are in it
+			g.putIn(p, g.stakes)
+			if p.owesSmall && i != small {
+				g.postDead(p, g.stakes/2)
+			}
+			g.roundLogger.Printf("%s posts %d owed\n", *p.Name, p.Bet)
+		case p.owesSmall:
+			g.postDead(p, g.stakes/2)
+			g.roundLogger.Printf("%s posts %d owed\n", *p.Name, p.Pot)
+		}
+		p.owesBig, p.owesSmall = false, false
+	}
+}
+
+// Put chips straight into the pot without counting them towards the player's bet
+func (g *Game) postDead(p *Player, chips uint64) {
+	if chips >= p.Chips {
+		chips = p.Chips
+		p.Status |= PSTATUS_ALL_IN
+	}
+	p.Chips -= chips
+	p.Pot += chips
+}
@@ -0,0 +1,98 @@
+package poker
+
+import (
+	"fmt"
+)
+
+// Spectators sit on the rail: they can watch (only public information, unless the table runs a
+// delayed TV feed) and chat amongst themselves, but they cannot see or talk to the table itself.
+// A spectator can still ask for a seat with AddPlayer and waits on the rail until they get one.
+
+func (g *Game) spectatorIndex(name *string) int {
+	for i, s := range g.spectators {
+		if *s == *name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *Game) AddSpectator(name *string, joinCode *string) (*string, bool, error) {
+	if g.Private() && (joinCode == nil || *joinCode != *g.joinCode) {
+		return nil, false, nil
+	}
+	if name == nil {
+		n := randPlayerName()
+		name = &n
+	}
+	if _, found := g.getPlayer(name); found {
+		return nil, false, fmt.Errorf("Player with name %s exists\n", *name)
+	}
+	if g.spectatorIndex(name) >= 0 {
+		return nil, false, fmt.Errorf("Spectator with name %s exists\n", *name)
+	}
+	g.spectators = append(g.spectators, name)
+	g.roundLogger.Printf("%s is watching\n", *name)
+	return name, true, nil
+}
+
+// Spectators who leave the rail also give up their place on the waitlist
+func (g *Game) RemoveSpectator(name *string) (bool, error) {
+	i := g.spectatorIndex(name)
+	if i < 0 {
+		return false, nil
+	}
+	g.spectators = append(g.spectators[:i], g.spectators[i+1:]...)
+	g.LeaveWaitlist(name)
+	return true, nil
+}
+
+func (g *Game) Spectators() []string {
+	spectators := make([]string, 0, len(g.spectators))
+	for _, s := range g.spectators {
+		spectators = append(spectators, *s)
+	}
+	return spectators
+}
+
+// Hole cards are only shown on the rail once the hand is over (so that the rail cannot tell the
+// table what anyone is holding) and the TV delay has passed since they were dealt
+func (g *Game) tvRevealed() bool {
+	if g.tvDelay == 0 || g.dealtAt.IsZero() || g.handInProgress() {
+		return false
+	}
+	return g.now().Sub(g.dealtAt) >= g.tvDelay
+}
+
+func (g *Game) SpectatorPlayers() []*PlayerInfo {
+	players := g.Players()
+	if g.tvRevealed() {
+		return players
+	}
+	for _, p := range players {
+		for i := range p.Cards {
+			if !p.Up[i] {
+				p.Cards[i] = Card(NoCards)
+			}
+		}
+	}
+	return players
+}
+
+func (g *Game) RailMessage(name *string, msg *string) (bool, error) {
+	if g.spectatorIndex(name) < 0 {
+		return false, nil
+	}
+	if msg == nil || *msg == "" {
+		return false, fmt.Errorf("Cannot send an empty message to the rail")
+	}
+	g.rail = append(g.rail, fmt.Sprintf("%s: %s", *name, *msg))
+	if len(g.rail) > MAX_RAIL_MESSAGES {
+		g.rail = g.rail[len(g.rail)-MAX_RAIL_MESSAGES:]
+	}
+	return true, nil
+}
+
+func (g *Game) RailChat() []string {
+	return append([]string{}, g.rail...)
+}
@@ -3,6 +3,7 @@ package poker
 import (
 	"testing"
 	"fmt"
+	"strings"
 )
 
 // TODO finish this unit-testing (at least in a single-threaded environment)
@@ -28,11 +29,11 @@ func pointer(c string) *string {
 
 func wrap(
 	test func(GameLike, *testing.T),
-	init func(*string, *GameInitArgs) (GameLike, error),
+	init func(*string, *GameInitArgs) (*string, GameLike, error),
 	creator string,
 	args *GameInitArgs,
 	t *testing.T) {
-	game, err := init(&creator, args)
+	_, game, err := init(&creator, args)
 	if err != nil {
 		t.Fatalf("Error initializing game: `%v`\n", err)
 	}
@@ -137,7 +138,7 @@ func TestPublicPlayersPlayingStakesManyPlayPauseAllowed(t *testing.T) {
 		for i := 0; i < len(players); i++ {
 			p := players[i].Name
 			previously, ok := found[p]
-			if previously || ok {
+			if previously || !ok {
 				t.Fatalf("Previously: %v, ok: %v, shold have been not ok", previously, ok)
 			}
 			found[p] = true
@@ -244,17 +245,32 @@ func TestPotsManyInBettingRound(t *testing.T) {
 
 func TestChangeGameNameAllowed(t *testing.T) {
 	wrap(func(game GameLike, t *testing.T) {
-		// TODO test
+		changed, err := game.ChangeGameName(pointer(creator), pointer("renamed"))
+		if !changed || err != nil || *game.(*Game).name != "renamed" {
+			t.Fatalf("Changed %v, while err was `%v`, but expected the game to be renamed", changed, err)
+		}
+		// no name picks a random one
+		changed, err = game.ChangeGameName(pointer(creator), nil)
+		if name := *game.(*Game).name; !changed || err != nil || name == "renamed" || !strings.Contains(name, "-game-") {
+			t.Fatalf("Changed %v to `%s`, while err was `%v`, but expected a random name", changed, name, err)
+		}
 	}, New, creator, &GameInitArgs{
-		// TODO args
+		Name: pointer(game_name),
+		Public: true,
 	}, t)
 }
 
 func TestChangeGameNameNotAllowed(t *testing.T) {
 	wrap(func(game GameLike, t *testing.T) {
-		// TODO test
+		foe := "foe"
+		game.AddPlayer(&foe, nil)
+		changed, err := game.ChangeGameName(&foe, pointer("renamed"))
+		if changed || *game.(*Game).name != game_name {
+			t.Fatalf("Changed the name when should have not: `%v`", err)
+		}
 	}, New, creator, &GameInitArgs{
-		// TODO args
+		Name: pointer(game_name),
+		Public: true,
 	}, t)
 }
 
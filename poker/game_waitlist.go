@@ -0,0 +1,100 @@
+package poker
+
+import (
+	"fmt"
+	"time"
+)
+
+type waitlistEntry struct {
+	name    *string
+	offered time.Time // When they were offered a seat (zero if they have not been)
+}
+
+// A table with no maximum is never full
+func (g *Game) tableFull() bool {
+	return g.maxPlayers > 0 && uint64(len(g.seats)) >= g.maxPlayers
+}
+
+func (g *Game) waitlistIndex(name *string) int {
+	for i, e := range g.waitlist {
+		if *e.name == *name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *Game) removeFromWaitlist(i int) {
+	g.waitlist = append(g.waitlist[:i], g.waitlist[i+1:]...)
+}
+
+// Drop expired offers and offer every free seat to the front of the waitlist. Anyone who
+// has been offered a seat holds onto it until they accept or their offer expires.
+func (g *Game) offerSeats() {
+	now := g.now()
+	kept := g.waitlist[:0]
+	for _, e := range g.waitlist {
+		if !e.offered.IsZero() && now.Sub(e.offered) >= g.offerWindow {
+			g.roundLogger.Printf("%s did not take their seat in time\n", *e.name)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	g.waitlist = kept
+
+	free := len(g.waitlist)
+	if g.maxPlayers > 0 {
+		free = int(g.maxPlayers) - len(g.seats)
+	}
+	for _, e := range g.waitlist {
+		if free <= 0 {
+			break
+		}
+		if e.offered.IsZero() {
+			e.offered = now
+			g.roundLogger.Printf("%s is offered a seat\n", *e.name)
+		}
+		free--
+	}
+}
+
+func (g *Game) Waitlist() []WaitlistEntry {
+	g.offerSeats()
+	entries := make([]WaitlistEntry, 0, len(g.waitlist))
+	for _, e := range g.waitlist {
+		entries = append(entries, WaitlistEntry{
+			Name:    *e.name,
+			Offered: !e.offered.IsZero(),
+			Expires: e.offered.Add(g.offerWindow),
+		})
+	}
+	return entries
+}
+
+// Take a seat that was offered; players who have not been offered a seat yet keep waiting
+func (g *Game) AcceptSeat(name *string) (bool, error) {
+	g.offerSeats()
+	i := g.waitlistIndex(name)
+	if i < 0 {
+		return false, fmt.Errorf("%s is not waiting for a seat", *name)
+	}
+	if g.waitlist[i].offered.IsZero() {
+		return false, nil
+	}
+	g.removeFromWaitlist(i)
+	if _, err := g.seatPlayer(name); err != nil {
+		return false, fmt.Errorf("Failed to seat %s: `%v`", *name, err)
+	}
+	g.roundLogger.Printf("%s takes a seat\n", *name)
+	return true, nil
+}
+
+func (g *Game) LeaveWaitlist(name *string) (bool, error) {
+	i := g.waitlistIndex(name)
+	if i < 0 {
+		return false, nil
+	}
+	g.removeFromWaitlist(i)
+	g.offerSeats()
+	return true, nil
+}
@@ -0,0 +1,217 @@
+package poker
+
+import (
+	"math/bits"
+)
+
+// Hands are evaluated into a single comparable uint64 so that showdowns are
+// just integer comparisons. The category sits above the kickers, and kickers
+// are stored as card values (2 through 14, Ace high) in descending order of
+// importance, four bits each. For example a pair of nines with an Ace, King and
+// Two kicker would be HAND_PAIR<<20 | 9<<16 | 14<<12 | 13<<8 | 2<<4. Variants that rank the
+// categories differently (i.e. short-deck) put the rank of the category above it.
+
+// Hand Categories
+const (
+	HAND_HIGH_CARD uint64 = (iota + 1)
+	HAND_PAIR
+	HAND_TWO_PAIR
+	HAND_THREE_OF_A_KIND
+	HAND_STRAIGHT
+	HAND_FLUSH
+	HAND_FULL_HOUSE
+	HAND_FOUR_OF_A_KIND
+	HAND_STRAIGHT_FLUSH
+	HAND_FIVE_OF_A_KIND // Only with wild cards
+)
+
+const handCategoryShift = 20
+const handRankShift = 24
+
+var handCategoryNames = map[uint64]string{
+	HAND_HIGH_CARD:       "High Card",
+	HAND_PAIR:            "Pair",
+	HAND_TWO_PAIR:        "Two Pair",
+	HAND_THREE_OF_A_KIND: "Three of a Kind",
+	HAND_STRAIGHT:        "Straight",
+	HAND_FLUSH:           "Flush",
+	HAND_FULL_HOUSE:      "Full House",
+	HAND_FOUR_OF_A_KIND:  "Four of a Kind",
+	HAND_STRAIGHT_FLUSH:  "Straight Flush",
+	HAND_FIVE_OF_A_KIND:  "Five of a Kind",
+}
+
+// return the category of an evaluated hand (i.e. HAND_FLUSH)
+func HandCategory(value uint64) uint64 {
+	return (value >> handCategoryShift) & 0xF
+}
+
+// return a short name for the category of an evaluated hand
+func HandCategoryName(value uint64) string {
+	return handCategoryNames[HandCategory(value)]
+}
+
+// pack a category and up to five kicker values into a comparable hand value
+func handValue(category uint64, kickers ...uint64) uint64 {
+	value := category << handCategoryShift
+	shift := handCategoryShift
+	for _, k := range kickers {
+		shift -= 4
+		value |= k << shift
+	}
+	return value
+}
+
+// return the value (2 through 14) of the highest card in the set or zero if it is empty
+func cardValue(cardset CardSet) uint64 {
+	if cardset & ^_Ace1s == 0 {
+		return 0
+	}
+	return uint64(bits.Len64(uint64(cardset & ^_Ace1s))-1)/4 + 1
+}
+
+// return up to n distinct card values in descending order (aces are high)
+func highValues(cardset CardSet, n int) []uint64 {
+	values := make([]uint64, 0, n)
+	quad := _Ace2s
+	for value := uint64(14); value >= 2 && len(values) < n; value-- {
+		if cardset&quad > 0 {
+			values = append(values, value)
+		}
+		quad >>= 4
+	}
+	return values
+}
+
+// return the value of the highest card of the highest straight in the set or zero
+// (a wheel, that is 5 4 3 2 Ace, is five high)
+func straightHigh(cardset CardSet) uint64 {
+	var run uint64 = 0
+	var high uint64 = 0
+	quad := _Ace1s
+	for value := uint64(1); value <= 14; value++ {
+		if cardset&quad > 0 {
+			run++
+			if run >= 5 {
+				high = value
+			}
+		} else {
+			run = 0
+		}
+		quad <<= 4
+	}
+	return high
+}
+
+// Evaluate the best five card high hand that uses exactly two cards from the hole and exactly
+// three from the board (as in Omaha)
+func EvaluateOmaha(hole CardSetLike, board CardSetLike) uint64 {
+	return evaluateOmaha(hole.Bits(), board.Bits(), evaluate)
+}
+
+func evaluateOmaha(hole CardSet, board CardSet, evaluate func(CardSet) uint64) uint64 {
+	var best uint64 = 0
+	for twos := NewCombinations(hole, 2); twos.Next(); {
+		for threes := NewCombinations(board, 3); threes.Next(); {
+			if v := evaluate(twos.Set() | threes.Set()); v > best {
+				best = v
+			}
+		}
+	}
+	return best
+}
+
+// Short-deck (six plus) is played without the twos through fives, so the ace plays low in A 6 7 8 9
+// and flushes are rarer than full houses (so they beat them). Some tables also rank three of a kind
+// above straights.
+const ShortDeck CardSet = AllCards & ^(Twos | Threes | Fours | Fives)
+
+var shortDeckRanks = map[uint64]uint64{
+	HAND_HIGH_CARD:       1,
+	HAND_PAIR:            2,
+	HAND_TWO_PAIR:        3,
+	HAND_THREE_OF_A_KIND: 4,
+	HAND_STRAIGHT:        5,
+	HAND_FULL_HOUSE:      6,
+	HAND_FLUSH:           7,
+	HAND_FOUR_OF_A_KIND:  8,
+	HAND_STRAIGHT_FLUSH:  9,
+}
+
+// Evaluate the best five card short-deck hand inside of the set (which should only hold short-deck cards)
+func EvaluateShortDeck(cards CardSetLike, tripsBeatStraights bool) uint64 {
+	return evaluateShortDeck(cards.Bits(), tripsBeatStraights)
+}
+
+func evaluateShortDeck(cardset CardSet, tripsBeatStraights bool) uint64 {
+	var best uint64 = 0
+	consider := func(value uint64) {
+		rank := shortDeckRanks[HandCategory(value)]
+		if tripsBeatStraights && HandCategory(value) == HAND_THREE_OF_A_KIND {
+			rank = shortDeckRanks[HAND_STRAIGHT]
+		} else if tripsBeatStraights && HandCategory(value) == HAND_STRAIGHT {
+			rank = shortDeckRanks[HAND_THREE_OF_A_KIND]
+		}
+		if ranked := rank<<handRankShift | value; ranked > best {
+			best = ranked
+		}
+	}
+	// the regular ranking finds the best hand except for what short-deck ranks differently,
+	// and with no fives the low aces can stand in for them to find A 6 7 8 9
+	consider(evaluate(cardset))
+	lowAces := cardset | (cardset&_Ace1s)<<16
+	if sf := straightFlush(lowAces); sf > 0 {
+		consider(handValue(HAND_STRAIGHT_FLUSH, straightHigh(sf)))
+	}
+	if f := flush(cardset); f > 0 {
+		consider(handValue(HAND_FLUSH, highValues(f, 5)...))
+	}
+	if s := straight(lowAces); s > 0 {
+		consider(handValue(HAND_STRAIGHT, straightHigh(s)))
+	}
+	if highTriplet, _ := triplet(cardset); highTriplet > 0 {
+		consider(handValue(HAND_THREE_OF_A_KIND, append([]uint64{cardValue(highTriplet)}, highValues(cardset & ^highTriplet, 2)...)...))
+	}
+	return best
+}
+
+// Evaluate the best five card high hand inside of the set (which may contain any number of cards)
+// and return a value which compares higher for better hands and equal for hands that tie.
+func Evaluate(cards CardSetLike) uint64 {
+	return evaluate(cards.Bits())
+}
+
+// The evaluators are built on bit tricks, so they all work on the bit-vector underneath
+func evaluate(cardset CardSet) uint64 {
+	if sf := straightFlush(cardset); sf > 0 {
+		return handValue(HAND_STRAIGHT_FLUSH, straightHigh(sf))
+	}
+	if quads := fourOfAKind(cardset); quads > 0 {
+		return handValue(HAND_FOUR_OF_A_KIND, append([]uint64{cardValue(quads)}, highValues(cardset & ^quads, 1)...)...)
+	}
+	highTriplet, lowTriplet := triplet(cardset)
+	highPair, medPair, _ := pair(cardset)
+	if highTriplet > 0 && (lowTriplet > 0 || highPair > 0) {
+		over := cardValue(lowTriplet)
+		if v := cardValue(highPair); v > over {
+			over = v
+		}
+		return handValue(HAND_FULL_HOUSE, cardValue(highTriplet), over)
+	}
+	if f := flush(cardset); f > 0 {
+		return handValue(HAND_FLUSH, highValues(f, 5)...)
+	}
+	if s := straight(cardset); s > 0 {
+		return handValue(HAND_STRAIGHT, straightHigh(s))
+	}
+	if highTriplet > 0 {
+		return handValue(HAND_THREE_OF_A_KIND, append([]uint64{cardValue(highTriplet)}, highValues(cardset & ^highTriplet, 2)...)...)
+	}
+	if highPair > 0 && medPair > 0 {
+		return handValue(HAND_TWO_PAIR, append([]uint64{cardValue(highPair), cardValue(medPair)}, highValues(cardset & ^(highPair|medPair), 1)...)...)
+	}
+	if highPair > 0 {
+		return handValue(HAND_PAIR, append([]uint64{cardValue(highPair)}, highValues(cardset & ^highPair, 3)...)...)
+	}
+	return handValue(HAND_HIGH_CARD, highValues(cardset, 5)...)
+}
@@ -0,0 +1,110 @@
+package poker
+
+import (
+	"testing"
+)
+
+func TestEvaluateCategories(t *testing.T) {
+	const numTests = 11
+
+	var hands = [numTests]CardSet{
+		SpadesRoyalFlush | TwoOfClubs | ThreeOfDiamonds,
+		FiveOfHearts | FourOfHearts | ThreeOfHearts | TwoOfHearts | AceOfHearts | KingOfClubs,
+		Sevens | KingOfClubs | KingOfDiamonds | TwoOfSpades,
+		Threes & ^ThreeOfClubs | (Jacks & ^JackOfSpades) | TwoOfSpades,
+		Clubs & ^(AceOfClubs | KingOfClubs | QueenOfClubs | JackOfClubs | TenOfClubs | NineOfClubs | SixOfClubs),
+		AceOfSpades | KingOfClubs | QueenOfDiamonds | JackOfHearts | TenOfHearts | TwoOfClubs,
+		NineOfClubs | NineOfDiamonds | NineOfHearts | FourOfSpades | KingOfHearts,
+		NineOfClubs | NineOfDiamonds | FourOfHearts | FourOfSpades | KingOfHearts | KingOfClubs,
+		AceOfClubs | AceOfDiamonds | FourOfHearts | SevenOfSpades | KingOfHearts,
+		AceOfClubs | JackOfDiamonds | FourOfHearts | SevenOfSpades | KingOfHearts | TwoOfClubs,
+		NoCards,
+	}
+
+	var expectedCategories = [numTests]uint64{
+		HAND_STRAIGHT_FLUSH,
+		HAND_STRAIGHT_FLUSH,
+		HAND_FOUR_OF_A_KIND,
+		HAND_FULL_HOUSE,
+		HAND_FLUSH,
+		HAND_STRAIGHT,
+		HAND_THREE_OF_A_KIND,
+		HAND_TWO_PAIR,
+		HAND_PAIR,
+		HAND_HIGH_CARD,
+		HAND_HIGH_CARD,
+	}
+
+	for i := 0; i < numTests; i++ {
+		if category := HandCategory(Evaluate(like(hands[i]))); category != expectedCategories[i] {
+			t.Errorf("%s evaluated to category %d but expected %d", CardSetToString(hands[i]), category, expectedCategories[i])
+		}
+	}
+}
+
+// the first hand of each pair should beat the second
+func TestEvaluateOrdering(t *testing.T) {
+	var better = []CardSet{
+		// six high straight beats the wheel
+		SixOfClubs | FiveOfHearts | FourOfHearts | ThreeOfHearts | TwoOfDiamonds,
+		// kickers count
+		AceOfClubs | AceOfDiamonds | KingOfHearts | SevenOfSpades | FourOfHearts,
+		// two trips make a full house with the lower trips as the pair
+		Nines & ^NineOfSpades | Fives & ^FiveOfSpades | KingOfClubs,
+		// the best three pairs are used with the third pair as a kicker
+		Kings & ^(KingOfClubs|KingOfHearts) | Nines & ^(NineOfClubs|NineOfHearts) | Fours & ^(FourOfClubs|FourOfHearts) | TwoOfClubs,
+		// flush compares every card
+		AceOfHearts | JackOfHearts | NineOfHearts | SevenOfHearts | ThreeOfHearts,
+		// fours of a kind beat full houses
+		Twos | ThreeOfClubs,
+	}
+	var worse = []CardSet{
+		FiveOfHearts | FourOfHearts | ThreeOfHearts | TwoOfDiamonds | AceOfSpades,
+		AceOfHearts | AceOfSpades | QueenOfHearts | SevenOfClubs | FourOfSpades,
+		Nines & ^NineOfSpades | Fours & ^FourOfSpades | KingOfClubs,
+		Kings & ^(KingOfClubs|KingOfHearts) | Nines & ^(NineOfClubs|NineOfHearts) | ThreeOfClubs | ThreeOfHearts | TwoOfDiamonds,
+		AceOfSpades | JackOfSpades | NineOfSpades | SevenOfSpades | TwoOfSpades,
+		Aces & ^AceOfClubs | Kings & ^KingOfClubs,
+	}
+
+	for i := range better {
+		if Evaluate(like(better[i])) <= Evaluate(like(worse[i])) {
+			t.Errorf("%s should beat %s", CardSetToString(better[i]), CardSetToString(worse[i]))
+		}
+	}
+}
+
+func TestEvaluateTies(t *testing.T) {
+	// suits do not matter and neither do cards past the best five
+	a := AceOfClubs | KingOfDiamonds | QueenOfHearts | JackOfSpades | NineOfClubs | TwoOfHearts | ThreeOfSpades
+	b := AceOfHearts | KingOfClubs | QueenOfSpades | JackOfDiamonds | NineOfHearts | FourOfClubs | FiveOfDiamonds
+	if Evaluate(like(a)) != Evaluate(like(b)) {
+		t.Errorf("%s should tie %s", CardSetToString(a), CardSetToString(b))
+	}
+}
+
+func TestEvaluateOmahaUsesTwoHoleCards(t *testing.T) {
+	type omahaTest struct {
+		hole     CardSet
+		board    CardSet
+		expected uint64
+	}
+	var tests = []omahaTest{
+		// four hearts in the hole and one on the board is not a flush
+		{AceOfHearts | KingOfHearts | QueenOfHearts | JackOfHearts, TwoOfHearts | SevenOfClubs | EightOfDiamonds | NineOfSpades | ThreeOfClubs, HAND_HIGH_CARD},
+		// but two in the hole and three on the board is
+		{AceOfHearts | KingOfHearts | QueenOfClubs | JackOfClubs, TwoOfHearts | SevenOfHearts | EightOfHearts | NineOfSpades | ThreeOfClubs, HAND_FLUSH},
+		// quads on the board only play with a single board card
+		{AceOfClubs | KingOfDiamonds | QueenOfHearts | JackOfSpades, Sevens | TwoOfHearts, HAND_THREE_OF_A_KIND},
+		// a straight needs three board cards
+		{TenOfClubs | NineOfDiamonds | EightOfHearts | SevenOfSpades, SixOfClubs | KingOfDiamonds | KingOfHearts | TwoOfSpades | ThreeOfSpades, HAND_PAIR},
+		// a pocket pair makes a full house with a single board card and a board pair
+		{FiveOfClubs | FiveOfDiamonds | TwoOfHearts | TwoOfClubs, FiveOfHearts | KingOfDiamonds | KingOfHearts | TwoOfSpades | ThreeOfSpades, HAND_FULL_HOUSE},
+	}
+	for _, test := range tests {
+		if category := HandCategory(EvaluateOmaha(like(test.hole), like(test.board))); category != test.expected {
+			t.Errorf("%s with %s on the board evaluated to category %d but expected %d",
+				CardSetToString(test.hole), CardSetToString(test.board), category, test.expected)
+		}
+	}
+}
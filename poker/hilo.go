@@ -0,0 +1,93 @@
+package poker
+
+// Hi/lo split games (eight or better) split every pot between the best high hand and the best low
+// hand, where a low has to be five different cards no higher than eight (aces are low, straights
+// and flushes do not count). If nobody has a low the best high hand takes the whole pot, and one
+// hand can win both halves. Hands that tie split their half, so a tied low can win a quarter.
+
+// Cards that can make an eight-or-better low
+const eightOrBetter CardSet = Aces | Twos | Threes | Fours | Fives | Sixes | Sevens | Eights
+
+// Evaluate the best eight-or-better low inside of the set (as in Stud-8) or zero if there is none
+func EvaluateLowEight(cards CardSetLike) uint64 {
+	return evaluateLowEight(cards.Bits())
+}
+
+func evaluateLowEight(cardset CardSet) uint64 {
+	low := cardset & eightOrBetter
+	counts := valueCounts(low, true)
+	different := 0
+	for value := 1; value <= 8; value++ {
+		if counts[value] > 0 {
+			different++
+		}
+	}
+	if different < 5 {
+		return 0
+	}
+	return evaluateLowA5(low)
+}
+
+// Evaluate the best eight-or-better low that uses exactly two cards from the hole and exactly
+// three from the board (as in Omaha-8) or zero if there is none
+func EvaluateOmahaLowEight(hole CardSetLike, board CardSetLike) uint64 {
+	return evaluateOmaha(hole.Bits(), board.Bits(), evaluateLowEight)
+}
+
+// How the chips in a pot are split between hands
+type HiLoSplit struct {
+	High []uint64 // Chips each hand wins with its high hand
+	Low  []uint64 // Chips each hand wins with its low hand
+}
+
+// Split the chips of a pot between hands given the value of each hand's high and low (zero if
+// it has no low). Odd chips go to the high half and then to the earliest hands, so hands should
+// be given in order starting left of the button.
+func SplitHiLo(chips uint64, highs []uint64, lows []uint64) HiLoSplit {
+	split := HiLoSplit{High: make([]uint64, len(highs)), Low: make([]uint64, len(highs))}
+	highHalf := chips
+	for _, low := range lows {
+		if low > 0 {
+			highHalf = chips - chips/2
+			splitBest(chips/2, lows, split.Low)
+			break
+		}
+	}
+	splitBest(highHalf, highs, split.High)
+	return split
+}
+
+// Split chips evenly between the hands with the best value (odd chips go to the earliest of them)
+func splitBest(chips uint64, values []uint64, won []uint64) {
+	var best uint64 = 0
+	winners := 0
+	for _, v := range values {
+		if v > best {
+			best = v
+			winners = 0
+		}
+		if v == best {
+			winners++
+		}
+	}
+	if winners == 0 {
+		return
+	}
+	share := chips / uint64(winners)
+	odd := chips % uint64(winners)
+	for i, v := range values {
+		if v != best {
+			continue
+		}
+		won[i] += share
+		if odd > 0 {
+			won[i]++
+			odd--
+		}
+	}
+}
+
+// return the highest card of an evaluated low (i.e. 7 for 7 5 4 3 2)
+func lowHighCard(value uint64) uint64 {
+	return ((lowKeyMax - value) >> (handCategoryShift - 4)) & 0xF
+}
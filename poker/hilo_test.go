@@ -0,0 +1,78 @@
+package poker
+
+import (
+	"testing"
+)
+
+func TestEvaluateLowEightQualifies(t *testing.T) {
+	type lowTest struct {
+		cards     CardSet
+		qualifies bool
+	}
+	var tests = []lowTest{
+		{AceOfClubs | TwoOfDiamonds | ThreeOfHearts | FourOfSpades | FiveOfClubs, true},
+		{EightOfClubs | SevenOfDiamonds | SixOfHearts | FourOfSpades | TwoOfClubs | KingOfClubs | KingOfHearts, true},
+		// nines are too high
+		{NineOfClubs | SevenOfDiamonds | SixOfHearts | FourOfSpades | TwoOfClubs, false},
+		// pairs do not count towards the five cards
+		{EightOfClubs | EightOfDiamonds | SixOfHearts | FourOfSpades | TwoOfClubs | TwoOfHearts | KingOfClubs, false},
+	}
+	for _, test := range tests {
+		if low := EvaluateLowEight(like(test.cards)); (low > 0) != test.qualifies {
+			t.Errorf("Expected %s to qualify for low: %v", CardSetToString(test.cards), test.qualifies)
+		}
+	}
+
+	// the wheel beats 6 4 3 2 A
+	if EvaluateLowEight(like(Aces & ^AceOfSpades | FiveOfClubs | FourOfClubs | ThreeOfClubs | TwoOfClubs)) <= EvaluateLowEight(like(SixOfClubs|FourOfClubs|ThreeOfClubs|TwoOfClubs|AceOfClubs)) {
+		t.Errorf("Expected the wheel to be the best low")
+	}
+}
+
+func TestEvaluateOmahaLowEightUsesTwoHoleCards(t *testing.T) {
+	board := TwoOfClubs | FiveOfDiamonds | SevenOfHearts | KingOfSpades | QueenOfClubs
+	// only one low card in the hole
+	if low := EvaluateOmahaLowEight(like(AceOfClubs|KingOfHearts|QueenOfHearts|JackOfHearts), like(board)); low != 0 {
+		t.Errorf("Expected no low with a single low card in the hole")
+	}
+	// three low cards on the board and two in the hole
+	if low := EvaluateOmahaLowEight(like(AceOfClubs|ThreeOfHearts|QueenOfHearts|JackOfHearts), like(board)); lowHighCard(low) != 7 {
+		t.Errorf("Expected a seven low but got a %d low", lowHighCard(low))
+	}
+	// only two low cards on the board
+	if low := EvaluateOmahaLowEight(like(AceOfClubs|ThreeOfHearts|FourOfHearts|SixOfHearts), like(board & ^SevenOfHearts | NineOfHearts)); low != 0 {
+		t.Errorf("Expected no low with only two low cards on the board")
+	}
+}
+
+func TestSplitHiLo(t *testing.T) {
+	type splitTest struct {
+		chips        uint64
+		highs        []uint64
+		lows         []uint64
+		expectedHigh []uint64
+		expectedLow  []uint64
+	}
+	var tests = []splitTest{
+		// one hand scoops
+		{100, []uint64{2, 1}, []uint64{2, 1}, []uint64{50, 0}, []uint64{50, 0}},
+		// without a low the high takes everything
+		{100, []uint64{1, 2}, []uint64{0, 0}, []uint64{0, 100}, []uint64{0, 0}},
+		// the odd chip goes to the high half
+		{101, []uint64{2, 1}, []uint64{1, 2}, []uint64{51, 0}, []uint64{0, 50}},
+		// quartered: two hands tie for the low and one of them wins the high
+		{100, []uint64{3, 1, 2}, []uint64{5, 5, 0}, []uint64{50, 0, 0}, []uint64{25, 25, 0}},
+		// tied highs and a single low
+		{99, []uint64{3, 3, 1}, []uint64{0, 0, 4}, []uint64{25, 25, 0}, []uint64{0, 0, 49}},
+	}
+	for _, test := range tests {
+		split := SplitHiLo(test.chips, test.highs, test.lows)
+		for i := range test.highs {
+			if split.High[i] != test.expectedHigh[i] || split.Low[i] != test.expectedLow[i] {
+				t.Errorf("Splitting %d chips between highs %v and lows %v gave highs %v and lows %v but expected %v and %v",
+					test.chips, test.highs, test.lows, split.High, split.Low, test.expectedHigh, test.expectedLow)
+				break
+			}
+		}
+	}
+}
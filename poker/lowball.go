@@ -0,0 +1,125 @@
+package poker
+
+import (
+	"math/bits"
+)
+
+// Lowball games are won by the lowest hand. In A-5 (i.e. razz) aces are low and straights and
+// flushes do not count, so the best hand is 5 4 3 2 A. In 2-7 (i.e. 2-7 triple draw) aces are
+// high and straights and flushes count against you, so the best hand is 7 5 4 3 2 of mixed suits.
+// Low hands are evaluated into values that, just like Evaluate, compare higher for better hands so
+// showdowns are still integer comparisons. The key they are built from (lowKeyMax minus the value)
+// packs the hand the same way as a high hand, with aces as one in A-5.
+
+const lowKeyMax uint64 = 1<<(handCategoryShift+4) - 1
+
+// return the category of an evaluated low hand (i.e. HAND_PAIR)
+func LowHandCategory(value uint64) uint64 {
+	return HandCategory(lowKeyMax - value)
+}
+
+// return how many cards of each value are in the set, with aces counted as ones
+// (using the low ace bits) or as fourteens
+func valueCounts(cardset CardSet, acesLow bool) [15]uint64 {
+	var counts [15]uint64
+	quad := Twos
+	for value := 2; value <= 13; value++ {
+		counts[value] = uint64(bits.OnesCount64(uint64(cardset & quad)))
+		quad <<= 4
+	}
+	if acesLow {
+		counts[1] = uint64(bits.OnesCount64(uint64(cardset & _Ace1s)))
+	} else {
+		counts[14] = uint64(bits.OnesCount64(uint64(cardset & _Ace2s)))
+	}
+	return counts
+}
+
+// pack a hand of at most five cards by how many of its cards share a value: the category
+// comes from the largest groups and the kickers are the values of the groups from the
+// largest (and then highest) to the smallest
+func groupValue(counts [15]uint64) uint64 {
+	kickers := make([]uint64, 0, 5)
+	var largest, pairs uint64 = 0, 0
+	for n := uint64(4); n >= 1; n-- {
+		for value := uint64(14); value >= 1; value-- {
+			if counts[value] != n {
+				continue
+			}
+			kickers = append(kickers, value)
+			if largest == 0 {
+				largest = n
+			}
+			if n == 2 {
+				pairs++
+			}
+		}
+	}
+	category := HAND_HIGH_CARD
+	switch {
+	case largest == 4:
+		category = HAND_FOUR_OF_A_KIND
+	case largest == 3 && pairs > 0:
+		category = HAND_FULL_HOUSE
+	case largest == 3:
+		category = HAND_THREE_OF_A_KIND
+	case pairs >= 2:
+		category = HAND_TWO_PAIR
+	case pairs == 1:
+		category = HAND_PAIR
+	}
+	return handValue(category, kickers...)
+}
+
+// Evaluate the best five card A-5 low hand inside of the set (which may contain any number of cards)
+func EvaluateLowA5(cards CardSetLike) uint64 {
+	return evaluateLowA5(cards.Bits())
+}
+
+func evaluateLowA5(cardset CardSet) uint64 {
+	counts := valueCounts(cardset, true)
+	// Take the lowest different values first and only then pair up the lowest values
+	// (pairing up as few cards as possible)
+	var hand [15]uint64
+	n := 0
+	for size := uint64(1); size <= 4 && n < 5; size++ {
+		for value := 1; value <= 13 && n < 5; value++ {
+			if counts[value] >= size && hand[value] == size-1 {
+				hand[value]++
+				n++
+			}
+		}
+	}
+	return lowKeyMax - groupValue(hand)
+}
+
+// Evaluate the best five card 2-7 low hand inside of the set (which may contain any number of cards)
+func EvaluateLow27(cards CardSetLike) uint64 {
+	return evaluateLow27(cards.Bits())
+}
+
+func evaluateLow27(cardset CardSet) uint64 {
+	if cardset.Count() <= 5 {
+		return lowKeyMax - key27(cardset)
+	}
+	var best uint64 = 0
+	for fives := NewCombinations(cardset, 5); fives.Next(); {
+		if v := lowKeyMax - key27(fives.Set()); v > best {
+			best = v
+		}
+	}
+	return best
+}
+
+// The high hand of at most five cards where the ace only plays high (so A 2 3 4 5 is no straight)
+func key27(cardset CardSet) uint64 {
+	value := evaluate(cardset)
+	wheel := (value>>(handCategoryShift-4))&0xF == 5
+	switch {
+	case HandCategory(value) == HAND_STRAIGHT_FLUSH && wheel:
+		return handValue(HAND_FLUSH, 14, 5, 4, 3, 2)
+	case HandCategory(value) == HAND_STRAIGHT && wheel:
+		return handValue(HAND_HIGH_CARD, 14, 5, 4, 3, 2)
+	}
+	return value
+}
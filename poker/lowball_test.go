@@ -0,0 +1,200 @@
+package poker
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// A slow reference for lowball hands: try every five cards (as a list of values and suits) and keep
+// the lowest by comparing categories and then values from the most important down
+type refCard struct {
+	value uint64
+	suit  uint64
+}
+
+func refCards(cardset CardSet, acesLow bool) []refCard {
+	cards := make([]refCard, 0, 7)
+	for i := 4; i < 56; i++ {
+		if cardset&(1<<uint(i)) == 0 {
+			continue
+		}
+		c := refCard{value: uint64(i/4 + 1), suit: uint64(i % 4)}
+		if c.value == 14 && acesLow {
+			c.value = 1
+		}
+		cards = append(cards, c)
+	}
+	return cards
+}
+
+func refKey(hand []refCard, straightsAndFlushes bool) uint64 {
+	counts := map[uint64]uint64{}
+	for _, c := range hand {
+		counts[c.value]++
+	}
+	values := make([]uint64, 0, 5)
+	for v := range counts {
+		values = append(values, v)
+	}
+	// by how many share the value and then by value
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return values[i] > values[j]
+	})
+	flush, straight := false, false
+	if straightsAndFlushes && len(hand) == 5 {
+		flush = true
+		for _, c := range hand {
+			flush = flush && c.suit == hand[0].suit
+		}
+		straight = len(values) == 5 && values[0]-values[4] == 4
+	}
+	shape := make([]uint64, 0, 5)
+	for _, v := range values {
+		shape = append(shape, counts[v])
+	}
+	var category uint64
+	switch {
+	case straight && flush:
+		return handValue(HAND_STRAIGHT_FLUSH, values[0])
+	case shape[0] == 4:
+		category = HAND_FOUR_OF_A_KIND
+	case shape[0] == 3 && len(shape) > 1 && shape[1] == 2:
+		category = HAND_FULL_HOUSE
+	case flush:
+		category = HAND_FLUSH
+	case straight:
+		return handValue(HAND_STRAIGHT, values[0])
+	case shape[0] == 3:
+		category = HAND_THREE_OF_A_KIND
+	case shape[0] == 2 && len(shape) > 1 && shape[1] == 2:
+		category = HAND_TWO_PAIR
+	case shape[0] == 2:
+		category = HAND_PAIR
+	default:
+		category = HAND_HIGH_CARD
+	}
+	return handValue(category, values...)
+}
+
+func refLow(cardset CardSet, acesLow bool) uint64 {
+	cards := refCards(cardset, acesLow)
+	if len(cards) <= 5 {
+		return lowKeyMax - refKey(cards, !acesLow)
+	}
+	var best uint64 = 0
+	hand := make([]refCard, 5)
+	var choose func(from int, k int)
+	choose = func(from int, k int) {
+		if k == 5 {
+			if v := lowKeyMax - refKey(hand, !acesLow); v > best {
+				best = v
+			}
+			return
+		}
+		for i := from; i < len(cards); i++ {
+			hand[k] = cards[i]
+			choose(i+1, k+1)
+		}
+	}
+	choose(0, 0)
+	return best
+}
+
+func TestLowballCategories(t *testing.T) {
+	type lowTest struct {
+		cards    CardSet
+		evaluate func(CardSetLike) uint64
+		expected uint64
+	}
+	var tests = []lowTest{
+		// A-5 ignores straights and flushes and picks the lowest cards
+		{FiveOfHearts | FourOfHearts | ThreeOfHearts | TwoOfHearts | AceOfHearts, EvaluateLowA5, HAND_HIGH_CARD},
+		{Kings | QueenOfClubs, EvaluateLowA5, HAND_FOUR_OF_A_KIND},
+		{Kings | QueenOfClubs | SevenOfHearts | SixOfClubs, EvaluateLowA5, HAND_PAIR},
+		{AceOfClubs | AceOfDiamonds | TwoOfClubs | TwoOfHearts | ThreeOfClubs | ThreeOfSpades | FourOfClubs, EvaluateLowA5, HAND_PAIR},
+		{AceOfClubs | AceOfDiamonds | AceOfHearts | TwoOfClubs | TwoOfHearts | TwoOfSpades | ThreeOfClubs, EvaluateLowA5, HAND_TWO_PAIR},
+		// 2-7 counts them and the ace is high
+		{FiveOfHearts | FourOfHearts | ThreeOfHearts | TwoOfHearts | AceOfHearts, EvaluateLow27, HAND_FLUSH},
+		{FiveOfHearts | FourOfHearts | ThreeOfHearts | TwoOfHearts | AceOfClubs, EvaluateLow27, HAND_HIGH_CARD},
+		{SixOfHearts | FiveOfHearts | FourOfHearts | ThreeOfClubs | TwoOfHearts, EvaluateLow27, HAND_STRAIGHT},
+		{SixOfHearts | FiveOfHearts | FourOfHearts | ThreeOfClubs | TwoOfHearts | EightOfSpades, EvaluateLow27, HAND_HIGH_CARD},
+	}
+	for _, test := range tests {
+		if category := LowHandCategory(test.evaluate(like(test.cards))); category != test.expected {
+			t.Errorf("%s evaluated to low category %d but expected %d", CardSetToString(test.cards), category, test.expected)
+		}
+	}
+}
+
+// the first hand of each pair should beat the second
+func TestLowballOrdering(t *testing.T) {
+	type lowTest struct {
+		better   CardSet
+		worse    CardSet
+		evaluate func(CardSetLike) uint64
+	}
+	var tests = []lowTest{
+		// the wheel is the nuts in A-5 and 7 5 4 3 2 is the nuts in 2-7
+		{FiveOfHearts | FourOfHearts | ThreeOfHearts | TwoOfHearts | AceOfHearts, SixOfClubs | FourOfHearts | ThreeOfHearts | TwoOfHearts | AceOfHearts, EvaluateLowA5},
+		{SevenOfClubs | FiveOfHearts | FourOfHearts | ThreeOfHearts | TwoOfHearts, SevenOfClubs | SixOfHearts | FourOfHearts | ThreeOfHearts | TwoOfHearts, EvaluateLow27},
+		{SevenOfClubs | FiveOfHearts | FourOfHearts | ThreeOfHearts | TwoOfHearts, FiveOfHearts | FourOfHearts | ThreeOfHearts | TwoOfHearts | AceOfClubs, EvaluateLow27},
+		// the highest card decides first
+		{EightOfClubs | FiveOfHearts | FourOfHearts | ThreeOfHearts | TwoOfHearts, NineOfClubs | FourOfHearts | ThreeOfHearts | TwoOfHearts | AceOfHearts, EvaluateLowA5},
+		// any unpaired hand beats a pair
+		{KingOfClubs | QueenOfHearts | JackOfHearts | TenOfHearts | EightOfHearts, AceOfClubs | AceOfHearts | TwoOfHearts | ThreeOfHearts | FourOfHearts, EvaluateLowA5},
+	}
+	for _, test := range tests {
+		if better, worse := test.evaluate(like(test.better)), test.evaluate(like(test.worse)); better <= worse {
+			t.Errorf("Expected %s to beat %s but got %d and %d", CardSetToString(test.better), CardSetToString(test.worse), better, worse)
+		}
+	}
+}
+
+// every five card hand evaluates the same as the reference
+func TestLowballMatchesReferenceExhaustively(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping every five card hand in short mode")
+	}
+	var deck [52]CardSet
+	for i := range deck {
+		deck[i] = nthCard(AllCards, i)
+	}
+	for a := 0; a < 52; a++ {
+		for b := a + 1; b < 52; b++ {
+			for c := b + 1; c < 52; c++ {
+				for d := c + 1; d < 52; d++ {
+					for e := d + 1; e < 52; e++ {
+						hand := deck[a] | deck[b] | deck[c] | deck[d] | deck[e]
+						if got, expected := EvaluateLowA5(like(hand)), refLow(hand, true); got != expected {
+							t.Fatalf("A-5 evaluated %s to %d but the reference got %d", CardSetToString(hand), got, expected)
+						}
+						if got, expected := EvaluateLow27(like(hand)), refLow(hand, false); got != expected {
+							t.Fatalf("2-7 evaluated %s to %d but the reference got %d", CardSetToString(hand), got, expected)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// hands of six and seven cards (as in draw and stud) evaluate the same as the reference
+func TestLowballMatchesReferenceOnLargerHands(t *testing.T) {
+	rng := rand.New(rand.NewSource(35))
+	for i := 0; i < 100000; i++ {
+		var hand CardSet = NoCards
+		for hand.Count() < 6+i%2 {
+			hand |= nthCard(AllCards, rng.Intn(52))
+		}
+		if got, expected := EvaluateLowA5(like(hand)), refLow(hand, true); got != expected {
+			t.Fatalf("A-5 evaluated %s to %d but the reference got %d", CardSetToString(hand), got, expected)
+		}
+		if got, expected := EvaluateLow27(like(hand)), refLow(hand, false); got != expected {
+			t.Fatalf("2-7 evaluated %s to %d but the reference got %d", CardSetToString(hand), got, expected)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package poker
+
+import (
+	"fmt"
+)
+
+// Mixed games switch between games at the same table between hands, so the seats, stacks, chat
+// and so on carry over from one game to the next. In a rotation (i.e. HORSE) every game is played
+// for a fixed number of hands in order. In dealer's choice the game is switched just as often but
+// to whichever game the player getting the button for it chose (or the same game if they did not).
+// Every switch changes the game's mode, which is announced to everyone at the table (the players,
+// the waitlist and the rail) through OnModeChange so that servers can push it as a game mode update.
+
+// Limit hold'em, Omaha hi/lo, razz, seven-card stud and stud eight-or-better (all fixed-limit)
+var HORSE_GAMES = []uint64{
+	GMODE_FIXED_LIMIT,
+	GMODE_POT_LIMIT_OMAHA | GMODE_HI_LO | GMODE_FIXED_LIMIT,
+	GMODE_SEVEN_CARD_STUD | GMODE_RAZZ | GMODE_FIXED_LIMIT,
+	GMODE_SEVEN_CARD_STUD | GMODE_FIXED_LIMIT,
+	GMODE_SEVEN_CARD_STUD | GMODE_HI_LO | GMODE_FIXED_LIMIT,
+}
+
+type Mixed struct {
+	*Game
+	handsPerGame  uint64
+	dealersChoice bool
+	game          int    // Which of the games is being played
+	chosen        int    // Which of the games is played next in dealer's choice
+	hands         uint64 // Hands played of the current game
+}
+
+func newMixed(g *Game, args *GameInitArgs) *Mixed {
+	handsPerGame := args.HandsPerGame
+	if handsPerGame == 0 {
+		handsPerGame = DEFAULT_HANDS_PER_GAME
+	}
+	return &Mixed{Game: g, handsPerGame: handsPerGame, dealersChoice: args.DealersChoice}
+}
+
+// The GameLike playing the current game
+func (m *Mixed) current() GameLike {
+	return variant(m.Game)
+}
+
+// The GameLike playing the next hand, which forced bets called now are for
+func (m *Mixed) next() GameLike {
+	if m.hands < m.handsPerGame {
+		return m.current()
+	}
+	return variantFor(m.Game, m.games[m.nextGame()])
+}
+
+// Which of the games is played once the current one has been played for enough hands
+func (m *Mixed) nextGame() int {
+	if m.dealersChoice {
+		return m.chosen
+	}
+	return (m.game + 1) % len(m.games)
+}
+
+// Move on to the next game once the current one has been played for enough hands
+func (m *Mixed) switchGame() {
+	next := m.nextGame()
+	m.hands = 0
+	m.game, m.chosen = next, next
+	if m.mode == m.games[next] {
+		return
+	}
+	m.mode = m.games[next]
+	name, _ := gameMode2Str(m.mode)
+	m.roundLogger.Printf("Switching games to %s\n", name)
+	m.announceMode()
+}
+
+// Tell everyone at the table (seated, waiting or on the rail) about the mode being played
+func (g *Game) announceMode() {
+	if g.modeListener == nil {
+		return
+	}
+	to := make([]string, 0, len(g.seats)+len(g.waitlist)+len(g.spectators))
+	for _, id := range g.seats {
+		to = append(to, *g.players[id].Name)
+	}
+	for _, e := range g.waitlist {
+		if g.spectatorIndex(e.name) < 0 {
+			to = append(to, *e.name)
+		}
+	}
+	to = append(to, g.Spectators()...)
+	g.modeListener(to, g.mode)
+}
+
+// Listen for switches of games; the listener is given everyone at the table and the new mode
+func (g *Game) OnModeChange(listener func([]string, uint64)) {
+	g.modeListener = listener
+}
+
+func (m *Mixed) NewRound() error {
+	if !m.handInProgress() && m.hands >= m.handsPerGame {
+		m.switchGame()
+	}
+	if err := m.current().NewRound(); err != nil {
+		return err
+	}
+	m.hands++
+	return nil
+}
+
+// The seat the button moves to if the next hand is played in a mode, the same way the games move
+// it: to the next player with chips who is sitting in and not waiting for the big blind (stud
+// has no blinds to wait for, and the other games deal them in when there is nobody else to play)
+func (m *Mixed) nextButton(mode uint64) int {
+	sittingIn := func(p *Player) bool {
+		return p.Chips > 0 && p.Status&(PSTATUS_LEAVING|PSTATUS_SITTING_OUT) == 0
+	}
+	active := func(p *Player) bool { return sittingIn(p) && p.Status&PSTATUS_WAIT_FOR_BB == 0 }
+	numActive := 0
+	for _, id := range m.seats {
+		if active(m.players[id]) {
+			numActive++
+		}
+	}
+	if mode&GMODE_SEVEN_CARD_STUD > 0 || numActive < 2 {
+		return m.nextSeat(m.button, sittingIn)
+	}
+	return m.nextSeat(m.button, active)
+}
+
+// Choose one of the table's games to play next in dealer's choice. The choice is made during the
+// last hand of the current game (or after it) by the player who gets the button in the first hand
+// of the game they choose.
+func (m *Mixed) ChooseGame(name *string, mode uint64) (bool, error) {
+	if !m.dealersChoice {
+		return false, fmt.Errorf("Games are played in order rather than by dealer's choice")
+	}
+	p, found := m.getPlayer(name)
+	if !found {
+		return false, fmt.Errorf("Could not find player %s to choose the game", *name)
+	}
+	mode, err := checkMode(mode | DEFAULT_MODE)
+	if err != nil {
+		return false, err
+	}
+	chosen := -1
+	for i, game := range m.games {
+		if game == mode {
+			chosen = i
+		}
+	}
+	if chosen < 0 {
+		return false, fmt.Errorf("Game mode %d is not one of the games at this table", mode)
+	}
+	if m.hands < m.handsPerGame {
+		return false, fmt.Errorf("The next game can only be chosen in the last hand of this one")
+	}
+	if seat := m.nextButton(mode); seat < 0 || m.seated(seat) != p {
+		return false, fmt.Errorf("Only the player who gets the button next can choose the game")
+	}
+	m.chosen = chosen
+	m.roundLogger.Printf("%s chooses the next game\n", *p.Name)
+	return true, nil
+}
+
+func (m *Mixed) Increment() (bool, error) {
+	return m.current().Increment()
+}
+
+func (m *Mixed) Straddle(admin *string, straddle uint64) (bool, error) {
+	return m.next().Straddle(admin, straddle)
+}
+
+func (m *Mixed) BombPot(admin *string) (bool, error) {
+	return m.next().BombPot(admin)
+}
+
+func (m *Mixed) RunItOffer() []string {
+	return m.current().RunItOffer()
+}
+
+func (m *Mixed) AgreeRunIt(name *string, times uint64) (bool, error) {
+	return m.current().AgreeRunIt(name, times)
+}
+
+// Only dealer's choice games let the button choose the game
+func (g *Game) ChooseGame(name *string, mode uint64) (bool, error) {
+	return false, fmt.Errorf("Only mixed games played by dealer's choice can choose the game")
+}
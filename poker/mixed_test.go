@@ -0,0 +1,167 @@
+package poker
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fold every hand until one player is left and resolve
+func foldHand(t *testing.T, g GameLike, game *Game) {
+	for turnName(game) != "" {
+		mustMove(t, game, MTYPE_FOLD, 0, turnName(game))
+	}
+	if _, err := g.Resolve(); err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+}
+
+func TestHORSERotatesGames(t *testing.T) {
+	m := newPlayingTable(t, 3, &GameInitArgs{Stakes: 100, Games: HORSE_GAMES, HandsPerGame: 2}).(*Mixed)
+	defer m.Teardown()
+	total := totalChips(m.Game)
+
+	info, err := ioutil.ReadFile(filepath.Join(*m.gameDir, gameInitName))
+	if err != nil || !strings.Contains(string(info), `"CONST_STAKES FIXED_LIMIT SEVEN_CARD_STUD RAZZ"`) {
+		t.Fatalf("Expected razz among the mixed games in %s but got `%s`: `%v`", gameInitName, info, err)
+	}
+
+	// every game is played twice in order (and then it starts over) with the same players
+	cards := []int{2, 4, 7, 7, 7, 2}
+	for hand := 0; hand < 2*len(cards); hand++ {
+		game := hand / 2
+		if err := m.NewRound(); err != nil {
+			t.Fatalf("Failed to start hand %d: `%v`", hand, err)
+		}
+		if mode := m.Mode(); mode != HORSE_GAMES[game%len(HORSE_GAMES)]|DEFAULT_MODE {
+			t.Fatalf("Expected hand %d to be played in mode %d but it is %d", hand, HORSE_GAMES[game%len(HORSE_GAMES)]|DEFAULT_MODE, mode)
+		}
+		for _, p := range m.Players() {
+			if len(p.Cards) != cards[game] {
+				t.Fatalf("Expected %s to be dealt %d cards in hand %d but got %d", p.Name, cards[game], hand, len(p.Cards))
+			}
+		}
+		if len(m.Players()) != 3 {
+			t.Fatalf("Expected everyone to stay seated but there are %d players", len(m.Players()))
+		}
+		foldHand(t, m, m.Game)
+	}
+	if totalChips(m.Game) != total {
+		t.Fatalf("Expected %d chips on the table after switching games but there are %d", total, totalChips(m.Game))
+	}
+}
+
+func TestSwitchingGamesIsAnnounced(t *testing.T) {
+	m := newPlayingTable(t, 2, &GameInitArgs{Stakes: 100, Games: HORSE_GAMES, HandsPerGame: 1}).(*Mixed)
+	defer m.Teardown()
+	m.AddSpectator(pointer("rail"), nil)
+	var heard []string
+	var modes []uint64
+	m.OnModeChange(func(to []string, mode uint64) {
+		heard = to
+		modes = append(modes, mode)
+	})
+
+	for hand := 0; hand < 2; hand++ {
+		if err := m.NewRound(); err != nil {
+			t.Fatalf("Failed to start hand %d: `%v`", hand, err)
+		}
+		foldHand(t, m, m.Game)
+	}
+	if len(modes) != 1 || modes[0] != HORSE_GAMES[1]|DEFAULT_MODE || modes[0] != m.Mode() {
+		t.Fatalf("Expected one switch to Omaha hi/lo to be announced but got %v", modes)
+	}
+	if strings.Join(heard, " ") != creator+" p1 rail" {
+		t.Fatalf("Expected the players and the rail to hear about the switch but got %v", heard)
+	}
+}
+
+func TestForcedBetsAreCalledForTheNextGame(t *testing.T) {
+	m := newPlayingTable(t, 3, &GameInitArgs{Stakes: 100, Games: []uint64{0, GMODE_SEVEN_CARD_STUD}, HandsPerGame: 1}).(*Mixed)
+	defer m.Teardown()
+
+	// stud is played after this hand so it cannot be straddled or bombed
+	if err := m.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	if straddled, err := m.Straddle(pointer(creator), STRADDLE_UTG); straddled || err == nil {
+		t.Fatalf("Expected the stud hand not to be straddled (straddled = %v)", straddled)
+	}
+	if bombed, err := m.BombPot(pointer(creator)); bombed || err == nil {
+		t.Fatalf("Expected the stud hand not to be a bomb pot (bombed = %v)", bombed)
+	}
+	foldHand(t, m, m.Game)
+
+	// and hold'em is played after the stud hand
+	if err := m.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	if bombed, err := m.BombPot(pointer(creator)); !bombed || err != nil {
+		t.Fatalf("Failed to call a bomb pot for the hold'em hand (bombed = %v): `%v`", bombed, err)
+	}
+	foldHand(t, m, m.Game)
+	if err := m.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	if m.Mode() != DEFAULT_MODE || m.bettingRound != BROUND_FLOP || m.middle[2] == NoCards {
+		t.Fatalf("Expected the hold'em hand to be a bomb pot (mode = %d, betting round = %d)", m.Mode(), m.bettingRound)
+	}
+}
+
+func TestDealersChoice(t *testing.T) {
+	g := newPlayingGame(t, 2, &GameInitArgs{})
+	defer g.Teardown()
+	if chosen, err := g.ChooseGame(pointer(creator), GMODE_TRIPLE_DRAW); chosen || err == nil {
+		t.Fatalf("Expected choosing a game to need dealer's choice (chosen = %v)", chosen)
+	}
+	m := newPlayingTable(t, 3, &GameInitArgs{Stakes: 100, Games: []uint64{0, GMODE_TRIPLE_DRAW}, HandsPerGame: 1, DealersChoice: true}).(*Mixed)
+	defer m.Teardown()
+
+	// the creator has the button in the first hand so p1 chooses the game after it, and the same
+	// game is played until someone chooses
+	if err := m.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	if chosen, err := m.ChooseGame(pointer(creator), GMODE_TRIPLE_DRAW); chosen || err == nil {
+		t.Fatalf("Expected the button of the hand being played not to choose the next game (chosen = %v)", chosen)
+	}
+	if chosen, err := m.ChooseGame(pointer("p1"), GMODE_SEVEN_CARD_STUD); chosen || err == nil {
+		t.Fatalf("Expected stud not to be one of the games (chosen = %v)", chosen)
+	}
+	foldHand(t, m, m.Game)
+	if err := m.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	if m.Mode() != DEFAULT_MODE || *m.seated(m.button).Name != "p1" {
+		t.Fatalf("Expected hold'em to be played again without a choice but the mode is %d", m.Mode())
+	}
+
+	// p2 gets the button next, chooses during the hand and deals the game they chose
+	if chosen, err := m.ChooseGame(pointer("p1"), GMODE_TRIPLE_DRAW); chosen || err == nil {
+		t.Fatalf("Expected only the next button to choose the game (chosen = %v)", chosen)
+	}
+	if chosen, err := m.ChooseGame(pointer("p2"), GMODE_TRIPLE_DRAW); !chosen || err != nil {
+		t.Fatalf("Failed to choose triple draw (chosen = %v): `%v`", chosen, err)
+	}
+	foldHand(t, m, m.Game)
+	if err := m.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	if m.Mode()&GMODE_TRIPLE_DRAW == 0 || len(m.Players()[0].Cards) != 5 {
+		t.Fatalf("Expected triple draw to be played but the mode is %d", m.Mode())
+	}
+	if button := *m.seated(m.button).Name; button != "p2" {
+		t.Fatalf("Expected p2 to have the button in the game they chose but %s has it", button)
+	}
+
+	// with more than one hand per game the choice waits for the last one
+	m2 := newPlayingTable(t, 3, &GameInitArgs{Stakes: 100, Games: []uint64{0, GMODE_TRIPLE_DRAW}, HandsPerGame: 2, DealersChoice: true}).(*Mixed)
+	defer m2.Teardown()
+	if err := m2.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	if chosen, err := m2.ChooseGame(pointer("p1"), GMODE_TRIPLE_DRAW); chosen || err == nil {
+		t.Fatalf("Expected the game not to be chosen before the last hand of the current one (chosen = %v)", chosen)
+	}
+}
@@ -0,0 +1,138 @@
+package poker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Outs are the cards still to come that improve a hold'em hand into a better category. Only cards
+// the hole cards play with count, so a card that pairs the board (or makes a flush or straight on
+// the board by itself) is not an out. Draws are found by adding cards to the hand and asking the
+// flush and straight detectors whether they complete anything: one card that completes a flush is a
+// flush draw, two or more values that complete a straight are an open-ended draw (or a double
+// gutshot, which has just as many outs) and one value is a gutshot. On the flop, needing both the
+// turn and the river is a backdoor draw. The odds of improving by the river are exact since every
+// turn and river is tried.
+
+// Draws
+const (
+	DRAW_FLUSH uint64 = 1 << iota
+	DRAW_OPEN_ENDED
+	DRAW_GUTSHOT
+	DRAW_BACKDOOR_FLUSH
+	DRAW_BACKDOOR_STRAIGHT
+)
+
+var drawNames = []struct {
+	draw uint64
+	name string
+}{
+	{DRAW_FLUSH, "Flush Draw"},
+	{DRAW_OPEN_ENDED, "Open-Ended"},
+	{DRAW_GUTSHOT, "Gutshot"},
+	{DRAW_BACKDOOR_FLUSH, "Backdoor Flush"},
+	{DRAW_BACKDOOR_STRAIGHT, "Backdoor Straight"},
+}
+
+// return the names of the draws (i.e. "Flush Draw, Gutshot") or an empty string for none
+func DrawNames(draws uint64) string {
+	names := make([]string, 0, len(drawNames))
+	for _, d := range drawNames {
+		if draws&d.draw > 0 {
+			names = append(names, d.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+type Outs struct {
+	Value   uint64             // The hand as it is now (as from Evaluate)
+	Outs    map[uint64]CardSet // The next cards that improve the hand by the category they improve it to
+	Count   int                // How many cards improve the hand
+	Draws   uint64             // The draws the hand has (i.e. DRAW_FLUSH | DRAW_GUTSHOT)
+	Odds    map[uint64]float64 // The probability of making at least each better category by the river
+	Improve float64            // The probability of improving at all by the river
+}
+
+// return the category the hand improves to with the extra cards or zero if it does not improve
+// (or the board makes it by itself)
+func improvedCategory(hole CardSet, board CardSet, current uint64) uint64 {
+	category := HandCategory(evaluate(hole | board))
+	if category <= current || category <= HandCategory(evaluate(board)) {
+		return 0
+	}
+	return category
+}
+
+// return whether adding the extra cards completes something the hand and the board did not have
+// (and the board does not have by itself)
+func completes(detect func(CardSet) CardSet, hole CardSet, board CardSet, extra CardSet) bool {
+	return detect(hole|board) == 0 && detect(hole|board|extra) > 0 && detect(board|extra) == 0
+}
+
+// Find the outs and draws of hold'em hole cards on a flop or turn
+func FindOuts(hole CardSet, board CardSet) (*Outs, error) {
+	if hole.Count() != 2 {
+		return nil, fmt.Errorf("Outs are for two hole cards but got %s", CardSetToString(hole))
+	}
+	if n := board.Count(); n != 3 && n != 4 {
+		return nil, fmt.Errorf("Outs are for a flop or a turn but got %s", CardSetToString(board))
+	}
+	if hole&board & ^_Ace1s != 0 {
+		return nil, fmt.Errorf("Hole cards %s are on the board", CardSetToString(hole&board))
+	}
+	value := evaluate(hole | board)
+	current := HandCategory(value)
+	o := &Outs{Value: value, Outs: map[uint64]CardSet{}, Odds: map[uint64]float64{}}
+
+	deck := AllCards.Remove(hole | board)
+	straightValues := map[uint64]bool{}
+	for _, card := range deck.Cards() {
+		if category := improvedCategory(hole, board|card, current); category > 0 {
+			o.Outs[category] |= card
+			o.Count++
+		}
+		if completes(flush, hole, board, card) {
+			o.Draws |= DRAW_FLUSH
+		}
+		if completes(straight, hole, board, card) {
+			straightValues[cardValue(card)] = true
+		}
+	}
+	switch {
+	case len(straightValues) >= 2:
+		o.Draws |= DRAW_OPEN_ENDED
+	case len(straightValues) == 1:
+		o.Draws |= DRAW_GUTSHOT
+	}
+
+	// every turn and river (or just every river) and what it makes
+	hits := map[uint64]int{}
+	improved, runouts := 0, 0
+	for c := NewCombinations(deck, 5-board.Count()); c.Next(); {
+		runouts++
+		runout := c.Set()
+		// on the flop it can take both cards to complete a flush or straight
+		if runout.Count() == 2 {
+			if o.Draws&DRAW_FLUSH == 0 && completes(flush, hole, board, runout) {
+				o.Draws |= DRAW_BACKDOOR_FLUSH
+			}
+			if o.Draws&(DRAW_OPEN_ENDED|DRAW_GUTSHOT) == 0 && completes(straight, hole, board, runout) {
+				o.Draws |= DRAW_BACKDOOR_STRAIGHT
+			}
+		}
+		category := improvedCategory(hole, board|runout, current)
+		if category == 0 {
+			continue
+		}
+		improved++
+		for c := current + 1; c <= category; c++ {
+			hits[c]++
+		}
+	}
+	for c, n := range hits {
+		o.Odds[c] = float64(n) / float64(runouts)
+	}
+	o.Improve = float64(improved) / float64(runouts)
+	return o, nil
+}
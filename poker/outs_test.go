@@ -0,0 +1,73 @@
+package poker
+
+import (
+	"math"
+	"testing"
+)
+
+func mustFindOuts(t *testing.T, hole CardSet, board CardSet) *Outs {
+	o, err := FindOuts(hole, board)
+	if err != nil {
+		t.Fatalf("Failed to find outs of %s on %s: `%v`", CardSetToString(hole), CardSetToString(board), err)
+	}
+	return o
+}
+
+func TestFindOutsOfAFlushDraw(t *testing.T) {
+	o := mustFindOuts(t, AceOfHearts|KingOfHearts, TwoOfHearts|SevenOfHearts|JackOfClubs)
+	if o.Draws != DRAW_FLUSH|DRAW_BACKDOOR_STRAIGHT || DrawNames(o.Draws) != "Flush Draw, Backdoor Straight" {
+		t.Fatalf("Expected a flush draw and a backdoor straight but got %s", DrawNames(o.Draws))
+	}
+	// nine hearts for the flush and three aces and three kings for a pair
+	if o.Outs[HAND_FLUSH].Count() != 9 || o.Outs[HAND_FLUSH] & ^Hearts != 0 || o.Outs[HAND_PAIR].Count() != 6 || o.Count != 15 {
+		t.Fatalf("Expected 9 flush outs and 6 pair outs but got %s and %s", CardSetToString(o.Outs[HAND_FLUSH]), CardSetToString(o.Outs[HAND_PAIR]))
+	}
+	// a flush unless neither the turn nor the river is one of the nine hearts
+	if flush := 1 - 703.0/1081; math.Abs(o.Odds[HAND_FLUSH]-flush) > 1e-12 {
+		t.Fatalf("Expected a flush or better %f of the time but got %f", flush, o.Odds[HAND_FLUSH])
+	}
+	if o.Improve <= o.Odds[HAND_FLUSH] || o.Odds[HAND_PAIR] != o.Improve {
+		t.Fatalf("Expected improving to include the flush and every pair but got %+v", o)
+	}
+}
+
+func TestFindOutsOfStraightDraws(t *testing.T) {
+	hole := NineOfClubs | EightOfDiamonds
+	o := mustFindOuts(t, hole, TenOfClubs|SevenOfSpades|TwoOfHearts)
+	if o.Draws&(DRAW_OPEN_ENDED|DRAW_GUTSHOT) != DRAW_OPEN_ENDED || o.Outs[HAND_STRAIGHT].Count() != 8 {
+		t.Fatalf("Expected an open-ended draw with 8 outs but got %s with %s", DrawNames(o.Draws), CardSetToString(o.Outs[HAND_STRAIGHT]))
+	}
+	o = mustFindOuts(t, hole, JackOfClubs|SevenOfSpades|TwoOfHearts)
+	if o.Draws&(DRAW_OPEN_ENDED|DRAW_GUTSHOT) != DRAW_GUTSHOT || o.Outs[HAND_STRAIGHT] != Tens {
+		t.Fatalf("Expected a gutshot to the tens but got %s with %s", DrawNames(o.Draws), CardSetToString(o.Outs[HAND_STRAIGHT]))
+	}
+
+	// on the turn there is only the river to come and nothing is a backdoor draw
+	o = mustFindOuts(t, hole, TenOfClubs|SevenOfSpades|TwoOfHearts|KingOfDiamonds)
+	if o.Count != 8+6 || math.Abs(o.Improve-14.0/46) > 1e-12 || o.Draws&(DRAW_BACKDOOR_FLUSH|DRAW_BACKDOOR_STRAIGHT) != 0 {
+		t.Fatalf("Expected 14 outs of 46 cards on the turn but got %+v", o)
+	}
+}
+
+func TestFindOutsIgnoresTheBoard(t *testing.T) {
+	// the last seven and a deuce improve the board rather than the hand
+	o := mustFindOuts(t, AceOfClubs|KingOfDiamonds, SevenOfHearts|SevenOfSpades|TwoOfDiamonds)
+	if HandCategory(o.Value) != HAND_PAIR || o.Count != 6 || o.Outs[HAND_TWO_PAIR] != (Aces|Kings) & ^(AceOfClubs|KingOfDiamonds) {
+		t.Fatalf("Expected only the aces and kings to be outs but got %+v", o)
+	}
+
+	var tests = []struct {
+		hole  CardSet
+		board CardSet
+	}{
+		{AceOfClubs, TwoOfClubs | ThreeOfClubs | FourOfClubs},
+		{AceOfClubs | KingOfClubs, TwoOfClubs | ThreeOfClubs},
+		{AceOfClubs | KingOfClubs, TwoOfClubs | ThreeOfClubs | FourOfClubs | FiveOfClubs | SixOfClubs},
+		{AceOfClubs | KingOfClubs, AceOfClubs | ThreeOfClubs | FourOfClubs},
+	}
+	for _, test := range tests {
+		if _, err := FindOuts(test.hole, test.board); err == nil {
+			t.Errorf("Expected an error finding outs of %s on %s", CardSetToString(test.hole), CardSetToString(test.board))
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package poker
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Seven-card stud is played at the same kind of table as hold'em (seats, stacks, the waitlist, the
+// rail and so on all work the same way) but every player gets their own seven cards instead of
+// sharing a board: two face down and one face up on third street, one face up on each of fourth,
+// fifth and sixth street and one face down on seventh street. Everyone antes, the lowest card
+// showing on third street brings it in (posts a bet of a quarter of a small bet) and after that
+// the best hand showing acts first. Betting is fixed-limit: small bets on third and fourth street
+// and big bets after that. If the deck runs out on seventh street a single card is dealt face up
+// in the middle for everyone to share. Stud-8 (GMODE_HI_LO) splits pots with the best low and razz
+// (GMODE_RAZZ) is won by the best A-5 low, so the highest card brings it in and the lowest hand
+// showing acts first.
+
+type Stud struct {
+	*Game
+}
+
+// The bring-in is a quarter of a small bet (the small bet is the stakes)
+func (s *Stud) bringIn() uint64 {
+	return s.stakes / 4
+}
+
+func (s *Stud) upCards(p *Player) CardSet {
+	var up CardSet = NoCards
+	for i, c := range p.Hand {
+		if i < len(p.up) && p.up[i] {
+			up |= CardSet(c)
+		}
+	}
+	return up
+}
+
+// Deal the kth card to every player in the hand, starting left of the button
+func (s *Stud) dealStreet(k int, up bool) error {
+	for j := 1; j <= len(s.seats); j++ {
+		if p := s.seated((s.button + j) % len(s.seats)); inHand(p) {
+			card, err := s.dealCard()
+			if err != nil {
+				return err
+			}
+			p.Hand[k] = card
+			p.up[k] = up
+		}
+	}
+	return nil
+}
+
+// Return the seat of the player in the hand whose up card is the worst: the lowest card by value
+// and then by suit (clubs being the lowest and spades the highest), or the highest in razz
+func (s *Stud) bringInSeat() int {
+	seat := -1
+	var worst CardSet = 0
+	for i, id := range s.seats {
+		p := s.players[id]
+		if !inHand(p) {
+			continue
+		}
+		// ignoring the low aces the lowest bit is the lowest card, and in razz ignoring the high
+		// aces the highest bit is the highest card
+		up := s.upCards(p) & ^_Ace1s
+		card := up & -up
+		worse := card < worst
+		if s.mode&GMODE_RAZZ > 0 {
+			up = s.upCards(p) & ^_Ace2s
+			card = CardSet(1) << uint(bits.Len64(uint64(up))-1)
+			worse = card > worst
+		}
+		if seat < 0 || worse {
+			seat, worst = i, card
+		}
+	}
+	return seat
+}
+
+// Return the seat of the player in the hand with the best hand showing, the lowest in razz (ties go
+// to the first player left of the button)
+func (s *Stud) bestShowingSeat() int {
+	seat := -1
+	var best uint64 = 0
+	for k := 1; k <= len(s.seats); k++ {
+		i := (s.button + k) % len(s.seats)
+		p := s.seated(i)
+		if !inHand(p) {
+			continue
+		}
+		v := EvaluateWild(newCardSetLike(s.upCards(p)), s.wildCards())
+		if s.mode&GMODE_RAZZ > 0 {
+			v = EvaluateLowA5(newCardSetLike(s.upCards(p)))
+		}
+		if seat < 0 || v > best {
+			seat, best = i, v
+		}
+	}
+	return seat
+}
+
+func (s *Stud) NewRound() error {
+	if !s.Playing() {
+		return fmt.Errorf("Cannot start a round while the game is paused")
+	}
+	if s.handInProgress() {
+		return fmt.Errorf("Cannot start a round while round %d is in progress", s.roundNum)
+	}
+	s.removeExpired()
+
+	// There are no blinds to miss so waiting for the big blind is the same as sitting in
+	active := func(p *Player) bool {
+		return p.Chips > 0 && p.Status&(PSTATUS_LEAVING|PSTATUS_SITTING_OUT) == 0
+	}
+	numActive := 0
+	for _, id := range s.seats {
+		p := s.players[id]
+		p.Hand = s.emptyHand()
+		p.up = make([]bool, len(p.Hand))
+		p.acted = false
+		if active(p) {
+			numActive++
+		}
+	}
+	if numActive < 2 {
+		return fmt.Errorf("Need at least two players with chips to start a round but have %d", numActive)
+	}
+	for _, id := range s.seats {
+		if p := s.players[id]; active(p) {
+			p.Status |= PSTATUS_PLAYING
+			p.Status &= ^PSTATUS_WAIT_FOR_BB
+			p.owesBig, p.owesSmall, p.orbitsOut = false, false, 0
+		}
+	}
+	// The button only marks the dealer (to break ties and give out odd chips)
+	s.button = s.nextSeat(s.button, active)
+
+	// Stud has no blinds to straddle or board to bomb, so nothing called for this hand carries over
+	s.takeForcedBets()
+
+	s.roundNum++
+	s.bettingRound = BROUND_PREFLOP
+	s.middle = [5]Card{NoCards, NoCards, NoCards, NoCards, NoCards}
+	s.pots = nil
+	s.newDeck()
+	for _, id := range s.seats {
+		if p := s.players[id]; inHand(p) {
+			s.postDead(p, s.ante)
+		}
+	}
+	for k, up := range []bool{false, false, true} {
+		if err := s.dealStreet(k, up); err != nil {
+			return err
+		}
+	}
+	s.dealtAt = s.now()
+
+	// The bring-in is not a full bet, so the first bet completes it to a small bet
+	seat := s.bringInSeat()
+	p := s.seated(seat)
+	s.putIn(p, s.bringIn())
+	p.acted = true
+	s.currentBet = p.Bet
+	s.minRaise = s.stakes - p.Bet
+	s.raises = 0
+	s.pots = s.buildPots()
+	s.roundLogger.Printf("Round %d: everyone antes %d and %s brings it in for %d\n", s.roundNum, s.ante, *p.Name, p.Bet)
+
+	s.turn = seat
+	s.advanceTurn()
+	return nil
+}
+
+// Deal the next street once the betting round is over
+func (s *Stud) Increment() (bool, error) {
+	if !s.handInProgress() {
+		return false, fmt.Errorf("Cannot increment when no round is in progress")
+	}
+	if !s.bettingDone() || s.liveCount() <= 1 || s.bettingRound == BROUND_SEVENTH_STREET {
+		return false, nil
+	}
+	s.collectBets()
+
+	// third street is dealt by NewRound so the kth card is dealt on street k + 1
+	k := int(s.bettingRound) + 2
+	if s.bettingRound+1 < BROUND_SEVENTH_STREET {
+		if err := s.dealStreet(k, true); err != nil {
+			return false, err
+		}
+	} else if s.deck.Count() >= s.liveCount() {
+		if err := s.dealStreet(k, false); err != nil {
+			return false, err
+		}
+	} else {
+		card, err := s.dealCard()
+		if err != nil {
+			return false, err
+		}
+		s.middle[0] = card
+		s.roundLogger.Printf("Not enough cards for seventh street so %s is shared\n", s.middle[0].String())
+	}
+	s.bettingRound++
+	s.roundLogger.Printf("Betting round %d\n", s.bettingRound)
+
+	s.currentBet = 0
+	s.minRaise = s.betUnit()
+	s.raises = 0
+	for _, id := range s.seats {
+		s.players[id].acted = false
+	}
+	// The best hand showing acts first
+	s.turn = (s.bestShowingSeat() + len(s.seats) - 1) % len(s.seats)
+	s.advanceTurn()
+	return true, nil
+}
+
+func (s *Stud) Straddle(admin *string, straddle uint64) (bool, error) {
+	return false, fmt.Errorf("Stud games cannot be straddled since there are no blinds")
+}
+
+func (s *Stud) BombPot(admin *string) (bool, error) {
+	return false, fmt.Errorf("Stud games cannot have bomb pots since there is no board")
+}
+
+// Boards are never run more than once in stud
+func (s *Stud) RunItOffer() []string {
+	return []string{}
+}
+
+func (s *Stud) AgreeRunIt(name *string, times uint64) (bool, error) {
+	return false, nil
+}
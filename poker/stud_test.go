@@ -0,0 +1,198 @@
+package poker
+
+import (
+	"testing"
+)
+
+func newStudGame(t *testing.T, n int, args *GameInitArgs) *Stud {
+	args.Mode |= GMODE_SEVEN_CARD_STUD
+	return newPlayingTable(t, n, args).(*Stud)
+}
+
+// the player with the lowest card showing (ignoring the low aces)
+func lowestShowing(s *Stud) string {
+	name := ""
+	var lowest CardSet = 0
+	for _, p := range s.Players() {
+		for i, c := range p.Cards {
+			card := CardSet(c.(Card)) & ^_Ace1s
+			if p.Up[i] && (name == "" || card < lowest) {
+				name, lowest = p.Name, card
+			}
+		}
+	}
+	return name
+}
+
+func TestStudBringInAndCompletion(t *testing.T) {
+	if _, _, err := New(pointer(creator), &GameInitArgs{Mode: GMODE_SEVEN_CARD_STUD | GMODE_POT_LIMIT_OMAHA}); err == nil {
+		t.Fatalf("Expected stud not to be playable as Omaha")
+	}
+	s := newStudGame(t, 3, &GameInitArgs{Stakes: 100, Ante: 10})
+	defer s.Teardown()
+	total := totalChips(s.Game)
+
+	if err := s.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	// two cards down and one up for everyone
+	for _, p := range s.Players() {
+		if len(p.Cards) != 7 || p.Cards[2] == Card(NoCards) || p.Cards[3] != Card(NoCards) {
+			t.Fatalf("Expected %s to be dealt three of seven cards but has %v", p.Name, p.Cards)
+		}
+		if p.Up[0] || p.Up[1] || !p.Up[2] {
+			t.Fatalf("Expected %s to have two cards down and one up but has %v", p.Name, p.Up)
+		}
+	}
+	// the rail only sees the cards that are up
+	for _, p := range s.SpectatorPlayers() {
+		if p.Cards[0] != Card(NoCards) || p.Cards[2] == Card(NoCards) {
+			t.Fatalf("Expected the rail to see only %s's up card but saw %v", p.Name, p.Cards)
+		}
+	}
+
+	// the lowest card showing brings it in and the next player completes to a small bet
+	bringer, _ := s.getPlayer(pointer(lowestShowing(s)))
+	if bringer.Bet != 25 || bringer.Pot != 10 || totalChips(s.Game) != total {
+		t.Fatalf("Expected %s to ante 10 and bring it in for 25 but put in %d and %d", *bringer.Name, bringer.Pot, bringer.Bet)
+	}
+	if pots := s.Pots(); len(pots) != 1 || pots[0] != 30 {
+		t.Fatalf("Expected a pot of 30 chips of antes but got %v", pots)
+	}
+	completer := turnName(s.Game)
+	if completer == *bringer.Name || completer == "" {
+		t.Fatalf("Expected the player after %s to act but it is %s's turn", *bringer.Name, completer)
+	}
+	if moved, err := s.Move(MTYPE_BET, 200, pointer(completer)); moved || err == nil {
+		t.Fatalf("Expected a bet over the limit to fail (moved = %v)", moved)
+	}
+	mustMove(t, s.Game, MTYPE_BET, 100, completer)
+	if s.currentBet != 100 || s.minRaise != 100 {
+		t.Fatalf("Expected a completion to 100 and raises of 100 but got %d and %d", s.currentBet, s.minRaise)
+	}
+}
+
+func TestStudPlaysToSeventhStreet(t *testing.T) {
+	s := newStudGame(t, 3, &GameInitArgs{Stakes: 100, Ante: 10})
+	defer s.Teardown()
+	total := totalChips(s.Game)
+
+	if err := s.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	for street := 3; street <= 7; street++ {
+		for turnName(s.Game) != "" {
+			mustMove(t, s.Game, MTYPE_CHECK|MTYPE_CALL, 0, turnName(s.Game))
+		}
+		if street == 7 {
+			break
+		}
+		if incremented, err := s.Increment(); !incremented || err != nil {
+			t.Fatalf("Failed to deal street %d (incremented = %v): `%v`", street+1, incremented, err)
+		}
+		for _, p := range s.Players() {
+			if p.Cards[street] == Card(NoCards) || p.Up[street] != (street < 6) {
+				t.Fatalf("Expected %s to be dealt card %d (up = %v) but has %v and %v", p.Name, street+1, street < 6, p.Cards, p.Up)
+			}
+		}
+		// the best hand showing acts first and bets are doubled from fifth street
+		best := s.bestShowingSeat()
+		if s.turn != best {
+			t.Fatalf("Expected seat %d with the best hand showing to act first but it is seat %d", best, s.turn)
+		}
+		if unit := s.betUnit(); street >= 4 && unit != 200 || street < 4 && unit != 100 {
+			t.Fatalf("Expected a bet of %d on street %d", unit, street+1)
+		}
+	}
+	if incremented, _ := s.Increment(); incremented {
+		t.Fatalf("Expected nothing to be dealt after seventh street")
+	}
+	if _, err := s.Resolve(); err != nil {
+		t.Fatalf("Failed to resolve: `%v`", err)
+	}
+	if totalChips(s.Game) != total {
+		t.Fatalf("Expected %d chips on the table after showdown but there are %d", total, totalChips(s.Game))
+	}
+}
+
+func TestStudRunsOutOfCardsWithoutPanicking(t *testing.T) {
+	if _, _, err := New(pointer(creator), &GameInitArgs{Mode: GMODE_SEVEN_CARD_STUD, MaxPlayers: 9}); err == nil {
+		t.Fatalf("Expected stud not to be playable by nine players")
+	}
+	if _, _, err := New(pointer(creator), &GameInitArgs{Games: []uint64{0, GMODE_SEVEN_CARD_STUD}, MaxPlayers: 9}); err == nil {
+		t.Fatalf("Expected a mixed game with stud not to be playable by nine players")
+	}
+	s := newStudGame(t, 3, &GameInitArgs{Stakes: 100, Ante: 10, MaxPlayers: MAX_STUD_PLAYERS})
+	defer s.Teardown()
+	if err := s.NewRound(); err != nil {
+		t.Fatalf("Failed to start round: `%v`", err)
+	}
+	for turnName(s.Game) != "" {
+		mustMove(t, s.Game, MTYPE_CHECK|MTYPE_CALL, 0, turnName(s.Game))
+	}
+	s.deck = newCardSetLike(NoCards)
+	if incremented, err := s.Increment(); incremented || err == nil {
+		t.Fatalf("Expected dealing from an empty deck to fail (incremented = %v)", incremented)
+	}
+}
+
+func TestFixedLimitCapsTheBetting(t *testing.T) {
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100, Mode: GMODE_FIXED_LIMIT})
+	defer g.Teardown()
+
+	// the big blind is the first bet so there are three raises left
+	mustNewRound(t, g)
+	mustMove(t, g, MTYPE_BET, 200, creator)
+	mustMove(t, g, MTYPE_BET, 250, "p1")
+	mustMove(t, g, MTYPE_BET, 300, "p2")
+	if moved, err := g.Move(MTYPE_BET, 300, pointer(creator)); moved || err == nil {
+		t.Fatalf("Expected a fifth bet to be capped (moved = %v)", moved)
+	}
+	callAround(t, g, creator, "p1")
+	if incremented, err := g.Increment(); !incremented || err != nil {
+		t.Fatalf("Failed to deal the flop (incremented = %v): `%v`", incremented, err)
+	}
+	if moved, err := g.Move(MTYPE_BET, 200, pointer("p1")); moved || err == nil {
+		t.Fatalf("Expected a big bet on the flop to fail (moved = %v)", moved)
+	}
+	mustMove(t, g, MTYPE_BET, 100, "p1")
+}
+
+func TestRazzBringsInTheHighestCard(t *testing.T) {
+	if _, _, err := New(pointer(creator), &GameInitArgs{Mode: GMODE_RAZZ}); err == nil {
+		t.Fatalf("Expected razz to need seven-card stud")
+	}
+	for _, razz := range []bool{false, true} {
+		args := &GameInitArgs{Stakes: 100}
+		if razz {
+			args.Mode = GMODE_RAZZ
+		}
+		s := newStudGame(t, 3, args)
+		defer s.Teardown()
+		if err := s.NewRound(); err != nil {
+			t.Fatalf("Failed to start round: `%v`", err)
+		}
+		up := map[string]Card{creator: Card(KingOfClubs), "p1": Card(AceOfSpades), "p2": Card(KingOfSpades)}
+		for name, c := range up {
+			p, _ := s.getPlayer(pointer(name))
+			p.Hand[2] = c
+		}
+		// the king of clubs is the lowest card in stud and the king of spades the highest in razz,
+		// where the ace is the best card showing
+		bringer, best := *s.seated(s.bringInSeat()).Name, *s.seated(s.bestShowingSeat()).Name
+		if !razz && (bringer != creator || best != "p1") || razz && (bringer != "p2" || best != "p1") {
+			t.Fatalf("Expected the right bring-in and best hand showing (razz = %v) but got %s and %s", razz, bringer, best)
+		}
+	}
+}
+
+func TestStudHasNoStraddlesOrBombPots(t *testing.T) {
+	s := newStudGame(t, 3, &GameInitArgs{Stakes: 100})
+	defer s.Teardown()
+	if straddled, err := s.Straddle(pointer(creator), STRADDLE_UTG); straddled || err == nil {
+		t.Fatalf("Expected stud not to be straddled (straddled = %v)", straddled)
+	}
+	if bombed, err := s.BombPot(pointer(creator)); bombed || err == nil {
+		t.Fatalf("Expected stud not to have bomb pots (bombed = %v)", bombed)
+	}
+}
@@ -0,0 +1,142 @@
+package poker
+
+// Wild cards stand for whichever cards make the best hand. Deuces-wild tables make every two wild
+// and joker tables add the two jokers to the deck, which are always wild. With enough wild cards a
+// hand can hold five cards of one value (five of a kind), which beats a straight flush. That is the
+// only hand where a wild card stands for a card that is already in the hand, so wild flushes take
+// the highest cards of the suit that are missing. There can be up to six wild cards in seven, so
+// rather than trying every substitution the best hand is found category by category from the top.
+
+// The cards that are wild at the table (none unless it plays with wild cards)
+func (g *Game) wildCards() CardSet {
+	var wild CardSet = NoCards
+	if g.mode&GMODE_DEUCES_WILD > 0 {
+		wild |= Twos
+	}
+	if g.mode&GMODE_JOKERS_WILD > 0 {
+		wild |= Jokers
+	}
+	return wild
+}
+
+// return every card of a value (2 through 14)
+func valueMask(value uint64) CardSet {
+	if value == 14 {
+		return Aces
+	}
+	return Twos << (4 * (value - 2))
+}
+
+// return the highest straight (by its highest value) that the cards and w wild cards make or zero
+func wildStraightHigh(cardset CardSet, w int) uint64 {
+	for high := uint64(14); high >= 5; high-- {
+		missing := 0
+		for value := high - 4; value <= high; value++ {
+			v := value
+			if v == 1 {
+				v = 14
+			}
+			if cardset&valueMask(v) == 0 {
+				missing++
+			}
+		}
+		if missing <= w {
+			return high
+		}
+	}
+	return 0
+}
+
+// Evaluate the best five card high hand inside of the set the same way as Evaluate, where the wild
+// cards in it (and the jokers) can be any card
+func EvaluateWild(cards CardSetLike, wild CardSetLike) uint64 {
+	return evaluateWild(cards.Bits(), wild.Bits())
+}
+
+func evaluateWild(cardset CardSet, wild CardSet) uint64 {
+	wild |= Jokers
+	w := (cardset & wild).Count()
+	natural := cardset & ^wild
+	if w == 0 {
+		return evaluate(natural)
+	}
+	counts := valueCounts(natural, false)
+	n := natural.Count() + w
+
+	if n >= 5 {
+		for v := uint64(14); v >= 2; v-- {
+			if counts[v]+uint64(w) >= 5 {
+				return handValue(HAND_FIVE_OF_A_KIND, v)
+			}
+		}
+		var high uint64 = 0
+		for s := uint(0); s < 4; s++ {
+			if h := wildStraightHigh(natural&(Clubs<<s), w); h > high {
+				high = h
+			}
+		}
+		if high > 0 {
+			return handValue(HAND_STRAIGHT_FLUSH, high)
+		}
+	}
+	if n >= 4 {
+		for v := uint64(14); v >= 2; v-- {
+			if counts[v]+uint64(w) >= 4 {
+				return handValue(HAND_FOUR_OF_A_KIND, append([]uint64{v}, highValues(natural & ^valueMask(v), 1)...)...)
+			}
+		}
+	}
+	if n >= 5 {
+		// the wild cards make up whatever the trips and the pair are missing
+		missing := func(value uint64, want uint64) int {
+			if counts[value] >= want {
+				return 0
+			}
+			return int(want - counts[value])
+		}
+		for t := uint64(14); t >= 2; t-- {
+			for p := uint64(14); p >= 2; p-- {
+				if p != t && missing(t, 3)+missing(p, 2) <= w {
+					return handValue(HAND_FULL_HOUSE, t, p)
+				}
+			}
+		}
+		var best uint64 = 0
+		for s := uint(0); s < 4; s++ {
+			suited := natural & (Clubs << s)
+			if suited.Count()+w < 5 {
+				continue
+			}
+			// the wild cards fill in the highest cards of the suit that are missing
+			filled := suited
+			for v, left := uint64(14), w; v >= 2 && left > 0; v-- {
+				if card := valueMask(v) & (Clubs << s); filled&card == 0 {
+					filled |= card
+					left--
+				}
+			}
+			if value := handValue(HAND_FLUSH, highValues(filled, 5)...); value > best {
+				best = value
+			}
+		}
+		if best > 0 {
+			return best
+		}
+		if high := wildStraightHigh(natural, w); high > 0 {
+			return handValue(HAND_STRAIGHT, high)
+		}
+	}
+	if n >= 3 {
+		for v := uint64(14); v >= 2; v-- {
+			if counts[v]+uint64(w) >= 3 {
+				return handValue(HAND_THREE_OF_A_KIND, append([]uint64{v}, highValues(natural & ^valueMask(v), 2)...)...)
+			}
+		}
+	}
+	// a single wild card pairs the highest card (there was no pair or it would have made trips)
+	pair := uint64(14)
+	if values := highValues(natural, 1); len(values) > 0 {
+		pair = values[0]
+	}
+	return handValue(HAND_PAIR, append([]uint64{pair}, highValues(natural & ^valueMask(pair), 3)...)...)
+}
@@ -0,0 +1,125 @@
+package poker
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func mustParseCardSet(t *testing.T, text string) CardSet {
+	cardset, err := ParseCardSet(text)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: `%v`", text, err)
+	}
+	return cardset
+}
+
+func TestJokers(t *testing.T) {
+	jokers, err := ParseCardSet("JR jb")
+	if err != nil || jokers != Jokers || CardSetToString(jokers) != "JR JB" {
+		t.Fatalf("%s (%v)", errMsg(jokers, Jokers), err)
+	}
+	if cards := (AceOfSpades | Jokers).Cards(); len(cards) != 3 || cards[0] != AceOfSpades || cards[1] != RedJoker || cards[2] != BlackJoker {
+		t.Fatalf("Expected the ace of spades and then the jokers but got %v", cards)
+	}
+	if NewCardArray(AllCards|Jokers).Bits() != AllCards|Jokers {
+		t.Fatalf("Expected a card array to hold the jokers")
+	}
+}
+
+func TestEvaluateWild(t *testing.T) {
+	var tests = []struct {
+		hand  string
+		wild  CardSet
+		value uint64
+	}{
+		{"Ah Ad Ac As JR", NoCards, handValue(HAND_FIVE_OF_A_KIND, 14)},
+		{"7h 7d 2c 2s JB", Twos, handValue(HAND_FIVE_OF_A_KIND, 7)},
+		{"Kh Kd JR JB 2c", Twos, handValue(HAND_FIVE_OF_A_KIND, 13)},
+		{"Kh Qh JR JB 2c", Twos, handValue(HAND_STRAIGHT_FLUSH, 14)},
+		{"Kh Qh Jh Th JR 3c 4d", NoCards, handValue(HAND_STRAIGHT_FLUSH, 14)},
+		{"Kh Qh Jh JR 3c 4d 5s", NoCards, handValue(HAND_PAIR, 13, 12, 11, 5)},
+		{"Ah 3d 4c 5s JR", NoCards, handValue(HAND_STRAIGHT, 5)},
+		{"Ah 3h 4h 5h 2s", Twos, handValue(HAND_STRAIGHT_FLUSH, 5)},
+		{"9c 9d 9s Kh JR", NoCards, handValue(HAND_FOUR_OF_A_KIND, 9, 13)},
+		{"9c 9d Kh Ks JR", NoCards, handValue(HAND_FULL_HOUSE, 13, 9)},
+		// the wild card is the highest heart that is missing rather than a second ace
+		{"Ah 9h 6h 3h JR Kc", NoCards, handValue(HAND_FLUSH, 14, 13, 9, 6, 3)},
+		{"Ah Kh 9h 6h JR JB", NoCards, handValue(HAND_FLUSH, 14, 13, 12, 11, 9)},
+		{"Ac 9d 8h 6s 5c 2d", Twos, handValue(HAND_STRAIGHT, 9)},
+		{"Kc 8d 7h 4s 2d", Twos, handValue(HAND_PAIR, 13, 8, 7, 4)},
+		{"Kc Kd 7h 4s 2d", Twos, handValue(HAND_THREE_OF_A_KIND, 13, 7, 4)},
+		{"JR JB", NoCards, handValue(HAND_PAIR, 14)},
+		// deuces are natural unless they are wild
+		{"Ah Ad 2c 2s Kh", NoCards, handValue(HAND_TWO_PAIR, 14, 2, 13)},
+	}
+	for _, test := range tests {
+		if value := EvaluateWild(like(mustParseCardSet(t, test.hand)), like(test.wild)); value != test.value {
+			t.Errorf("Expected %s to be worth %x (%s) but got %x (%s)", test.hand, test.value, HandCategoryName(test.value), value, HandCategoryName(value))
+		}
+	}
+	if EvaluateWild(like(mustParseCardSet(t, "2c 2d 2h 2s JR")), like(Twos)) <= EvaluateWild(like(mustParseCardSet(t, "Ah Kh Qh Jh Th")), like(Twos)) {
+		t.Fatalf("Expected five of a kind to beat a royal flush")
+	}
+}
+
+// Up to two wild cards, the best hand is the best of every card the wild cards could be (five of
+// a kind aside, which needs a card that is already in the hand)
+func TestEvaluateWildMatchesEverySubstitution(t *testing.T) {
+	rng := rand.New(rand.NewSource(48))
+	for i := 0; i < 400; i++ {
+		n, w := 5+i%3, 1+i%2
+		natural := randomHand(rng, n-w)
+		hand := natural | []CardSet{RedJoker, Jokers}[w-1]
+		value := EvaluateWild(like(hand), like(NoCards))
+		if HandCategory(value) == HAND_FIVE_OF_A_KIND {
+			continue
+		}
+		var best uint64 = 0
+		for c := NewCombinations(AllCards & ^natural, w); c.Next(); {
+			if v := Evaluate(like(natural | c.Set())); v > best {
+				best = v
+			}
+		}
+		if value != best {
+			t.Fatalf("Expected %s to be worth %x (%s) but got %x (%s)", CardSetToString(hand), best, HandCategoryName(best), value, HandCategoryName(value))
+		}
+	}
+}
+
+func TestWildCardTables(t *testing.T) {
+	for _, mode := range []uint64{GMODE_SHORT_DECK | GMODE_DEUCES_WILD, GMODE_HI_LO | GMODE_JOKERS_WILD, GMODE_SEVEN_CARD_STUD | GMODE_RAZZ | GMODE_JOKERS_WILD} {
+		if _, _, err := New(pointer(creator), &GameInitArgs{Mode: mode}); err == nil {
+			t.Errorf("Expected an error creating a game with wild cards and mode %d", mode)
+		}
+	}
+	if str, err := gameMode2Str(GMODE_DEUCES_WILD | GMODE_JOKERS_WILD); err != nil || str != "DEUCES_WILD JOKERS_WILD" {
+		t.Fatalf("Expected both wild card modes to be named but got %q (%v)", str, err)
+	}
+
+	g := newPlayingGame(t, 3, &GameInitArgs{Stakes: 100, Mode: GMODE_JOKERS_WILD | GMODE_DEUCES_WILD})
+	defer g.Teardown()
+	mustNewRound(t, g)
+	if left := g.deck.Count(); left != 54-6 || (g.deck.Bits()|dealtCards(g))&Jokers != Jokers {
+		t.Fatalf("Expected %d cards left from a deck with both jokers but there are %d", 54-6, left)
+	}
+
+	p1, _ := g.getPlayer(pointer("p1"))
+	p2, _ := g.getPlayer(pointer("p2"))
+	board := Card(KingOfClubs | KingOfDiamonds | KingOfSpades | FourOfSpades | NineOfHearts)
+	p1.Hand = []Card{Card(KingOfHearts), Card(SevenOfClubs)}
+	p2.Hand = []Card{Card(RedJoker), Card(TwoOfClubs)}
+	if v1, v2 := g.handValue(p1, board), g.handValue(p2, board); HandCategory(v2) != HAND_FIVE_OF_A_KIND || v2 <= v1 {
+		t.Fatalf("Expected a joker and a deuce to make five kings and beat four but got %x and %x", v2, v1)
+	}
+}
+
+// return every card dealt to the players
+func dealtCards(g *Game) CardSet {
+	var dealt CardSet = NoCards
+	for _, p := range g.Players() {
+		for _, c := range p.Cards {
+			dealt |= CardSet(c.(Card))
+		}
+	}
+	return dealt
+}
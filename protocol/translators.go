@@ -29,6 +29,12 @@ const (
 	NET_RQTYPE_SHOW_CARDS
 	NET_RQTYPE_SHOW_LEFT_CARD
 	NET_RQTYPE_SHOW_RIGHT_CARD
+	NET_RQTYPE_ACCEPT_SEAT
+	NET_RQTYPE_SIT_IN
+	NET_RQTYPE_SIT_IN_WAIT_FOR_BB
+	NET_RQTYPE_RUN_IT
+	NET_RQTYPE_DRAW
+	NET_RQTYPE_CHOOSE_GAME
 )
 
 const (
@@ -55,6 +61,12 @@ const (
 	UI_RQTYPE_SHOW_RIGHT_CARD                      // = NET_*
 	UI_RQTYPE_LOBBY_LIST
 	UI_RQTYPE_CLIENT_EXIT
+	UI_RQTYPE_ACCEPT_SEAT
+	UI_RQTYPE_SIT_IN
+	UI_RQTYPE_SIT_IN_WAIT_FOR_BB
+	UI_RQTYPE_RUN_IT
+	UI_RQTYPE_DRAW
+	UI_RQTYPE_CHOOSE_GAME
 )
 
 const (
@@ -125,6 +137,7 @@ type NetResponse struct {
 	Middle  [5]*poker.CardLike
 	Players []*poker.PlayerInfo
 	Pots    []uint64
+	Mode    uint64 // A change is a UI_RPTYPE_GAME_GMODE_UPDATE (mixed games change it between hands)
 }
 
 // Translators are effectively a lightweight interface used by internals of servers and clients to translate